@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 
 	"github.com/dlbarduzzi/bookshop/internal/bookshop"
 	"github.com/dlbarduzzi/bookshop/internal/database"
 	"github.com/dlbarduzzi/bookshop/internal/logging"
+	"github.com/dlbarduzzi/bookshop/internal/mailer"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
 	"github.com/dlbarduzzi/bookshop/internal/registry"
 	"github.com/dlbarduzzi/bookshop/internal/server"
 )
@@ -35,6 +38,18 @@ func start(ctx context.Context) error {
 
 	dbConfig := setDatabaseConfig(reg)
 	appConfig := setBookshopConfig(reg)
+	mailerConfig := setMailerConfig(reg)
+	paymentsConfig := setPaymentsConfig(reg)
+
+	mail, err := mailer.NewMailer(mailerConfig)
+	if err != nil {
+		return err
+	}
+
+	provider, err := payments.NewProvider(paymentsConfig)
+	if err != nil {
+		return err
+	}
 
 	db, err := database.NewDatabase(dbConfig)
 	if err != nil {
@@ -44,11 +59,13 @@ func start(ctx context.Context) error {
 	defer db.Close()
 	logger.Info("database connection established")
 
-	app, err := bookshop.NewBookshop(db, logger, appConfig)
+	app, err := bookshop.NewBookshop(db, mail, provider, logger, appConfig)
 	if err != nil {
 		return err
 	}
 
+	app.StartJobs()
+
 	srv := server.NewServer(app.Port(), logger)
 
 	srv.RunBeforeShutdown(func() {
@@ -69,6 +86,40 @@ func setDatabaseConfig(v *viper.Viper) *database.Config {
 
 func setBookshopConfig(v *viper.Viper) *bookshop.Config {
 	return &bookshop.Config{
-		Port: v.GetInt("APP_PORT"),
+		Port:           v.GetInt("APP_PORT"),
+		QueryTimeout:   v.GetDuration("DB_QUERY_TIMEOUT"),
+		LimiterEnabled: v.GetBool("APP_LIMITER_ENABLED"),
+		LimiterRPS:     v.GetFloat64("APP_LIMITER_RPS"),
+		LimiterBurst:   v.GetInt("APP_LIMITER_BURST"),
+		TrustedProxies: getStringList(v, "APP_TRUSTED_PROXIES"),
+		TrustedOrigins: getStringList(v, "APP_CORS_TRUSTED_ORIGINS"),
+		BannedWords:    getStringList(v, "APP_REVIEWS_BANNED_WORDS"),
+	}
+}
+
+func setMailerConfig(v *viper.Viper) *mailer.Config {
+	return &mailer.Config{
+		Host:     v.GetString("SMTP_HOST"),
+		Port:     v.GetInt("SMTP_PORT"),
+		Username: v.GetString("SMTP_USERNAME"),
+		Password: v.GetString("SMTP_PASSWORD"),
+		Sender:   v.GetString("SMTP_SENDER"),
+	}
+}
+
+func setPaymentsConfig(v *viper.Viper) *payments.Config {
+	return &payments.Config{
+		Provider:      v.GetString("PAYMENTS_PROVIDER"),
+		WebhookSecret: v.GetString("PAYMENTS_WEBHOOK_SECRET"),
+	}
+}
+
+func getStringList(v *viper.Viper, key string) []string {
+	list := make([]string, 0)
+	for _, value := range strings.Split(v.GetString(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
 	}
+	return list
 }
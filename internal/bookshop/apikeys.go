@@ -0,0 +1,136 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	defaultAPIKeyRPS   = 5
+	defaultAPIKeyBurst = 10
+)
+
+type createAPIKeyResponse struct {
+	Code   int           `json:"code"`
+	Key    string        `json:"key"`
+	APIKey *model.APIKey `json:"api_key"`
+}
+
+func (b *Bookshop) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+		RPS    float64  `json:"rps"`
+		Burst  int      `json:"burst"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	key := &model.APIKey{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		RPS:       input.RPS,
+		Burst:     input.Burst,
+		CreatedBy: b.contextGetUser(r).ID,
+	}
+
+	if key.RPS == 0 {
+		key.RPS = defaultAPIKeyRPS
+	}
+
+	if key.Burst == 0 {
+		key.Burst = defaultAPIKeyBurst
+	}
+
+	if model.ValidateAPIKey(v, key); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	plaintext, err := model.GenerateAPIKey(key)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err := b.models.APIKeys.Insert(r.Context(), key); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := createAPIKeyResponse{
+		Code:   http.StatusCreated,
+		Key:    plaintext,
+		APIKey: key,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type listAPIKeysResponse struct {
+	Code    int             `json:"code"`
+	APIKeys []*model.APIKey `json:"api_keys"`
+}
+
+func (b *Bookshop) listAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := b.models.APIKeys.GetAll(r.Context())
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := listAPIKeysResponse{
+		Code:    http.StatusOK,
+		APIKeys: keys,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type revokeAPIKeyResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	if err := b.models.APIKeys.Revoke(r.Context(), id); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := revokeAPIKeyResponse{
+		Code:    http.StatusOK,
+		Message: "API key successfully revoked.",
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,165 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
+)
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "customer user",
+			headers:  customerHeaders(),
+			body:     `{"name": "Partner", "scopes": ["books:read"]}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "invalid scope",
+			headers:  staffHeaders(),
+			body:     `{"name": "Partner", "scopes": ["books:write"]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  staffHeaders(),
+			body:     `{"name": "Partner", "scopes": ["books:read"]}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/api-keys", tc.body, tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+
+			var res createAPIKeyResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(res.Key, res.APIKey.Prefix) {
+				t.Errorf("expected key %s to start with prefix %s", res.Key, res.APIKey.Prefix)
+			}
+
+			if res.APIKey.RPS != defaultAPIKeyRPS || res.APIKey.Burst != defaultAPIKeyBurst {
+				t.Errorf("expected default limits; got %v and %d", res.APIKey.RPS, res.APIKey.Burst)
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "not found",
+			id:       "2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodDelete, "/api/v1/api-keys/"+tc.id, "", staffHeaders())
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.LimiterEnabled = true
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	headers := make(http.Header)
+	headers.Set("X-API-Key", "bsk_invalidkeyinvalidkeyinvalidkeyin")
+
+	code, _ := srv.request(t, http.MethodGet, "/api/v1/books", "", headers)
+	if code != http.StatusUnauthorized {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusUnauthorized, code)
+	}
+
+	headers.Set("X-API-Key", mocks.PartnerAPIKey)
+
+	code, _ = srv.request(t, http.MethodGet, "/api/v1/books", "", headers)
+	if code != http.StatusOK {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusOK, code)
+	}
+
+	// The mocked partner key allows a single request per second.
+	code, _ = srv.request(t, http.MethodGet, "/api/v1/books", "", headers)
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusTooManyRequests, code)
+	}
+}
+
+func TestAPIKeyWithBearerTokenIsRateLimited(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.LimiterEnabled = true
+	app.limiter = newLimiter(1, 1)
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	// A bogus api key must not let a bearer token request skip the limiter.
+	headers := customerHeaders()
+	headers.Set("X-API-Key", "bsk_invalidkeyinvalidkeyinvalidkeyin")
+
+	code, _ := srv.request(t, http.MethodGet, "/api/v1/books", "", headers)
+	if code != http.StatusOK {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusOK, code)
+	}
+
+	code, _ = srv.request(t, http.MethodGet, "/api/v1/books", "", headers)
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusTooManyRequests, code)
+	}
+}
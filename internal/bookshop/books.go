@@ -1,7 +1,10 @@
 package bookshop
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
 	"github.com/dlbarduzzi/bookshop/internal/jsontil"
@@ -16,8 +19,7 @@ type listBooksResponse struct {
 
 func (b *Bookshop) listBooksHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title      string
-		Categories []string
+		model.BookSearch
 		model.Filters
 	}
 
@@ -26,14 +28,44 @@ func (b *Bookshop) listBooksHandler(w http.ResponseWriter, r *http.Request) {
 
 	input.Title = b.readString(q, "title", "")
 	input.Categories = b.readCSV(q, "categories", []string{})
+	input.MinRating = b.readFloat(q, "min_rating", 0, v)
+	input.Currency = strings.ToUpper(b.readString(q, "currency", ""))
+	input.InStock = b.readBool(q, "in_stock", v)
 
 	input.Filters.Page = b.readInt(q, "page", 1, v)
 	input.Filters.PageSize = b.readInt(q, "page_size", 10, v)
 
 	input.Filters.Sort = b.readString(q, "sort", "id")
 	input.Filters.SortSafeList = []string{
-		"id", "title", "published_date", "page_count",
-		"-id", "-title", "-published_date", "-page_count",
+		"id", "title", "published_date", "page_count", "rating", "price",
+		"-id", "-title", "-published_date", "-page_count", "-rating", "-price",
+	}
+
+	// Price bounds and price sorting only make sense in a single currency,
+	// since amounts are in the minor unit of their currency. Sorting by price
+	// must name the currency, rather than quietly hiding books priced in others.
+	minPrice := b.readString(q, "min_price", "")
+	maxPrice := b.readString(q, "max_price", "")
+	sortByPrice := strings.TrimPrefix(input.Filters.Sort, "-") == "price"
+	if sortByPrice && input.Currency == "" {
+		v.AddError("currency", "must be provided when sorting by price")
+	}
+	if (minPrice != "" || maxPrice != "") && input.Currency == "" {
+		input.Currency = model.DefaultCurrency
+	}
+	input.MinPrice = b.readPrice(minPrice, input.Currency, "min_price", v)
+	input.MaxPrice = b.readPrice(maxPrice, input.Currency, "max_price", v)
+
+	if !validator.ValueInRange(input.MinRating, 0, 5) {
+		v.AddError("min_rating", "must be between 0 and 5")
+	}
+
+	if input.Currency != "" && !model.IsSupportedCurrency(input.Currency) {
+		v.AddError("currency", "must be a supported currency")
+	}
+
+	if input.MinPrice != nil && input.MaxPrice != nil && *input.MinPrice > *input.MaxPrice {
+		v.AddError("max_price", "must be greater than or equal to min_price")
 	}
 
 	if input.Filters.Validate(v); !v.IsValid() {
@@ -41,7 +73,7 @@ func (b *Bookshop) listBooksHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	books, metadata, err := b.models.Books.GetAll(input.Title, input.Categories, input.Filters)
+	books, metadata, err := b.models.Books.GetAll(r.Context(), input.BookSearch, input.Filters)
 	if err != nil {
 		b.serverError(w, r, err)
 		return
@@ -58,3 +90,212 @@ func (b *Bookshop) listBooksHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+type showBookResponse struct {
+	Code int         `json:"code"`
+	Book *model.Book `json:"book"`
+}
+
+func (b *Bookshop) showBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	book, err := b.models.Books.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := showBookResponse{
+		Code: http.StatusOK,
+		Book: book,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type createBookResponse struct {
+	Code int         `json:"code"`
+	Book *model.Book `json:"book"`
+}
+
+func (b *Bookshop) createBookHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Title         string       `json:"title"`
+		Authors       []string     `json:"authors"`
+		PublishedDate string       `json:"published_date"`
+		PageCount     int          `json:"page_count"`
+		Categories    []string     `json:"categories"`
+		Price         *model.Money `json:"price"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	book := &model.Book{
+		Title:         input.Title,
+		Authors:       input.Authors,
+		PublishedDate: input.PublishedDate,
+		PageCount:     input.PageCount,
+		Categories:    input.Categories,
+	}
+
+	// Books are free unless a price is given.
+	book.Price = model.Money{Currency: model.DefaultCurrency}
+	if input.Price != nil {
+		book.Price = *input.Price
+	}
+
+	if model.ValidateBook(v, book); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if err := b.models.Books.Insert(r.Context(), book); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := createBookResponse{
+		Code: http.StatusCreated,
+		Book: book,
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/api/v1/books/%d", book.ID))
+
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type updateBookResponse struct {
+	Code int         `json:"code"`
+	Book *model.Book `json:"book"`
+}
+
+func (b *Bookshop) updateBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	book, err := b.models.Books.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	var input struct {
+		Title         *string      `json:"title"`
+		Authors       []string     `json:"authors"`
+		PublishedDate *string      `json:"published_date"`
+		PageCount     *int         `json:"page_count"`
+		Categories    []string     `json:"categories"`
+		Price         *model.Money `json:"price"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if input.Title != nil {
+		book.Title = *input.Title
+	}
+	if input.Authors != nil {
+		book.Authors = input.Authors
+	}
+	if input.PublishedDate != nil {
+		book.PublishedDate = *input.PublishedDate
+	}
+	if input.PageCount != nil {
+		book.PageCount = *input.PageCount
+	}
+	if input.Categories != nil {
+		book.Categories = input.Categories
+	}
+	if input.Price != nil {
+		book.Price = *input.Price
+	}
+
+	if model.ValidateBook(v, book); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if err := b.models.Books.Update(r.Context(), book); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := updateBookResponse{
+		Code: http.StatusOK,
+		Book: book,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type deleteBookResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) deleteBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	if err := b.models.Books.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := deleteBookResponse{
+		Code:    http.StatusOK,
+		Message: "Book successfully deleted.",
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
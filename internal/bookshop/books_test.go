@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
 )
 
 func TestListBooksHandler(t *testing.T) {
@@ -25,6 +27,61 @@ func TestListBooksHandler(t *testing.T) {
 			query:    "?page=10",
 			wantCode: http.StatusOK,
 		},
+		{
+			name:     "invalid min_rating parameter",
+			query:    "?min_rating=6",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "rating filter and sort",
+			query:    "?min_rating=3.5&sort=-rating",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid min_price parameter",
+			query:    "?min_price=1.999",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "min_price above max_price",
+			query:    "?min_price=20&max_price=10",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unsupported currency",
+			query:    "?currency=XYZ",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "lowercase currency",
+			query:    "?currency=eur",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "price sort without currency",
+			query:    "?sort=price",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "price sort with lowercase currency",
+			query:    "?sort=-price&currency=usd",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid in_stock parameter",
+			query:    "?in_stock=yes",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "in_stock filter",
+			query:    "?in_stock=true",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "price filter and sort",
+			query:    "?min_price=5.00&max_price=25&currency=USD&sort=-price",
+			wantCode: http.StatusOK,
+		},
 		{
 			name:     "success",
 			query:    "",
@@ -77,3 +134,288 @@ func TestListBooksHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestShowBookHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "invalid id parameter",
+			id:       "not_an_integer",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "negative id parameter",
+			id:       "-1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "book not found",
+			id:       "2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.get(t, "/api/v1/books/"+tc.id)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				var res notFoundResponse
+
+				if err := json.Unmarshal([]byte(body), &res); err != nil {
+					t.Fatal(err)
+				}
+
+				wantMessage := "The requested resource could not be found."
+				if res.Message != wantMessage {
+					t.Errorf("expected error message to be %s; got %s", wantMessage, res.Message)
+				}
+				return
+			}
+
+			var res showBookResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			wantBookID := int64(1)
+			if res.Book == nil || res.Book.ID != wantBookID {
+				t.Fatalf("expected book id to be %d; got %v", wantBookID, res.Book)
+			}
+		})
+	}
+}
+
+func TestCreateBookHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "malformed body",
+			body:     `{"title": `,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid book",
+			body:     `{"title": ""}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid price",
+			body:     `{"title": "Test Book 2", "price": {"amount": 12.99, "currency": "USD"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "success",
+			body: `{
+				"title": "Test Book 2",
+				"authors": ["Test Author 2"],
+				"published_date": "2020-02-02",
+				"page_count": 200,
+				"categories": ["Drama"],
+				"price": {"amount": "12.99", "currency": "USD"}
+			}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/books", tc.body, staffHeaders())
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+
+			var res createBookResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			wantBookID := int64(2)
+			if res.Book == nil || res.Book.ID != wantBookID {
+				t.Fatalf("expected book id to be %d; got %v", wantBookID, res.Book)
+			}
+		})
+	}
+}
+
+func TestUpdateBookHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "book not found",
+			id:       "2",
+			body:     `{"title": "Test Book 2"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid book",
+			id:       "1",
+			body:     `{"page_count": 0}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			body:     `{"title": "Test Book 1 Updated"}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPatch, "/api/v1/books/"+tc.id, tc.body, staffHeaders())
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res updateBookResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			wantBookTitle := "Test Book 1 Updated"
+			if res.Book == nil || res.Book.Title != wantBookTitle {
+				t.Fatalf("expected book title to be %s; got %v", wantBookTitle, res.Book)
+			}
+
+			wantVersion := int32(2)
+			if res.Book.Version != wantVersion {
+				t.Errorf("expected book version to be %d; got %d", wantVersion, res.Book.Version)
+			}
+		})
+	}
+}
+
+func TestDeleteBookHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "book not found",
+			id:       "2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodDelete, "/api/v1/books/"+tc.id, "", staffHeaders())
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestBookWritePermissions(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "customer user",
+			token:    mocks.CustomerAuthenticationToken,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "staff user",
+			token:    mocks.AuthenticationToken,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			headers := make(http.Header)
+			if tc.token != "" {
+				headers.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			code, _ := srv.request(t, http.MethodDelete, "/api/v1/books/1", "", headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
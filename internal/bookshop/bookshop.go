@@ -1,32 +1,60 @@
 package bookshop
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+)
+
+const (
+	emailMaxAttempts = 3
+	emailRetryDelay  = time.Millisecond * 500
+
+	reservationSweepInterval = time.Minute
 )
 
 type Bookshop struct {
-	config *Config
-	logger *slog.Logger
-	models model.Models
-	wg     *sync.WaitGroup
+	config   *Config
+	logger   *slog.Logger
+	models   model.Models
+	mailer   emailSender
+	payments payments.Provider
+	limiter  *limiter
+	wg       *sync.WaitGroup
+	quit     chan struct{}
 }
 
-func NewBookshop(db *sql.DB, logger *slog.Logger, config *Config) (*Bookshop, error) {
+type emailSender interface {
+	Send(recipient string, templateFile string, data any) error
+}
+
+func NewBookshop(
+	db *sql.DB,
+	mailer emailSender,
+	provider payments.Provider,
+	logger *slog.Logger,
+	config *Config,
+) (*Bookshop, error) {
 	cfg, err := config.parse()
 	if err != nil {
 		return nil, err
 	}
 
 	return &Bookshop{
-		config: cfg,
-		logger: logger,
-		models: model.NewModels(db),
-		wg:     &sync.WaitGroup{},
+		config:   cfg,
+		logger:   logger,
+		models:   model.NewModels(db, cfg.QueryTimeout),
+		mailer:   mailer,
+		payments: provider,
+		limiter:  newLimiter(cfg.LimiterRPS, cfg.LimiterBurst),
+		wg:       &sync.WaitGroup{},
+		quit:     make(chan struct{}),
 	}, nil
 }
 
@@ -50,6 +78,71 @@ func (b *Bookshop) Background(fn func()) {
 	}()
 }
 
+// sendEmail delivers the email in the background, retrying a few times before
+// giving up so that transient SMTP failures do not lose the message.
+func (b *Bookshop) sendEmail(recipient string, templateFile string, data any) {
+	b.Background(func() {
+		var err error
+
+		for i := 1; i <= emailMaxAttempts; i++ {
+			if err = b.mailer.Send(recipient, templateFile, data); err == nil {
+				return
+			}
+			if i < emailMaxAttempts {
+				time.Sleep(emailRetryDelay * time.Duration(i))
+			}
+		}
+
+		b.logger.Error(
+			err.Error(),
+			slog.String("template", templateFile),
+			slog.Int("attempts", emailMaxAttempts),
+		)
+	})
+}
+
+// StartJobs runs the periodic jobs of the bookshop in the background until
+// Shutdown is called.
+func (b *Bookshop) StartJobs() {
+	b.Background(func() {
+		b.runEvery(reservationSweepInterval, b.expireReservations)
+	})
+}
+
+// runEvery calls fn right away and then once every interval, until the
+// bookshop shuts down.
+func (b *Bookshop) runEvery(interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn()
+
+		select {
+		case <-ticker.C:
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+// expireReservations fails the unpaid orders past their reservation, so their
+// stock is available again even when nobody checks out.
+func (b *Bookshop) expireReservations() {
+	ctx := context.Background()
+
+	expired, err := b.models.Orders.ExpireReservations(ctx)
+	if err != nil {
+		b.logger.Error(err.Error(), slog.Int("expired", expired))
+		return
+	}
+
+	if expired > 0 {
+		b.logger.Info("order reservations expired", slog.Int("expired", expired))
+	}
+}
+
 func (b *Bookshop) Shutdown() {
+	close(b.quit)
 	b.wg.Wait()
 }
@@ -2,24 +2,85 @@ package bookshop
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
 	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
+	"github.com/dlbarduzzi/bookshop/internal/logging"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
 )
 
+const testWebhookSecret = "whsec_test"
+
 func newTestBookshop(t *testing.T) *Bookshop {
 	t.Helper()
+
+	config, err := (&Config{Port: 8000}).parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	return &Bookshop{
-		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
-		models: mocks.NewModels(&sql.DB{}),
+		config:   config,
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		models:   mocks.NewModels(&sql.DB{}),
+		mailer:   &testMailer{},
+		payments: payments.NewFakeProvider(testWebhookSecret),
+		limiter:  newLimiter(config.LimiterRPS, config.LimiterBurst),
+		wg:       &sync.WaitGroup{},
+		quit:     make(chan struct{}),
 	}
 }
 
+type testMailer struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  int
+	templates []string
+}
+
+func (m *testMailer) Send(recipient string, templateFile string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.attempts++
+
+	if m.attempts <= m.failures {
+		return errors.New("test mailer failure")
+	}
+
+	m.templates = append(m.templates, templateFile)
+	return nil
+}
+
+func newTestRequest(t *testing.T, app *Bookshop, method string, urlPath string) *http.Request {
+	t.Helper()
+
+	r, err := http.NewRequest(method, urlPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r.WithContext(logging.LoggerWithContext(r.Context(), app.logger))
+}
+
+func staffHeaders() http.Header {
+	headers := make(http.Header)
+	headers.Set("Authorization", "Bearer "+mocks.AuthenticationToken)
+	return headers
+}
+
 type testServer struct {
 	*httptest.Server
 }
@@ -46,3 +107,88 @@ func (s *testServer) get(t *testing.T, urlPath string) (int, string) {
 	body = bytes.TrimSpace(body)
 	return res.StatusCode, string(body)
 }
+
+func (s *testServer) request(
+	t *testing.T,
+	method string,
+	urlPath string,
+	body string,
+	headers http.Header,
+) (int, string) {
+	req, err := http.NewRequest(method, s.URL+urlPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range headers {
+		req.Header[key] = value
+	}
+
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resBody = bytes.TrimSpace(resBody)
+	return res.StatusCode, string(resBody)
+}
+
+func TestSendEmail(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	mailer := &testMailer{failures: 2}
+	app.mailer = mailer
+
+	app.sendEmail("test@example.com", "user_welcome.tmpl", nil)
+	app.Shutdown()
+
+	if mailer.attempts != 3 {
+		t.Errorf("expected mailer attempts to be %d; got %d", 3, mailer.attempts)
+	}
+
+	if len(mailer.templates) != 1 || mailer.templates[0] != "user_welcome.tmpl" {
+		t.Errorf("expected user_welcome.tmpl to be sent; got %v", mailer.templates)
+	}
+}
+
+// sweepRecorder counts the sweeps of expired order reservations.
+type sweepRecorder struct {
+	model.OrderStore
+	sweeps atomic.Int32
+}
+
+func (r *sweepRecorder) ExpireReservations(ctx context.Context) (int, error) {
+	r.sweeps.Add(1)
+	return 0, nil
+}
+
+func TestStartJobs(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	orders := &sweepRecorder{OrderStore: app.models.Orders}
+	app.models.Orders = orders
+
+	app.StartJobs()
+
+	deadline := time.Now().Add(time.Second * 5)
+	for orders.sweeps.Load() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	app.Shutdown()
+
+	if orders.sweeps.Load() < 1 {
+		t.Error("expected order reservations to be swept")
+	}
+}
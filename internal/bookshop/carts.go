@@ -0,0 +1,199 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+type showCartResponse struct {
+	Code int         `json:"code"`
+	Cart *model.Cart `json:"cart"`
+}
+
+func (b *Bookshop) showCartHandler(w http.ResponseWriter, r *http.Request) {
+	cart, err := b.models.Carts.GetForUser(r.Context(), b.contextGetUser(r).ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := showCartResponse{
+		Code: http.StatusOK,
+		Cart: cart,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type addCartItemResponse struct {
+	Code int         `json:"code"`
+	Cart *model.Cart `json:"cart"`
+}
+
+func (b *Bookshop) addCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		BookID   int64 `json:"book_id"`
+		Quantity *int  `json:"quantity"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	quantity := 1
+	if input.Quantity != nil {
+		quantity = *input.Quantity
+	}
+
+	if input.BookID < 1 {
+		v.AddError("book_id", "must be provided")
+	}
+
+	if model.ValidateCartItemQuantity(v, quantity); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user := b.contextGetUser(r)
+
+	if err := b.models.Carts.AddItem(r.Context(), user.ID, input.BookID, quantity); err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			v.AddError("book_id", "book does not exist")
+			b.validationError(w, r, v.Errors)
+		case errors.Is(err, model.ErrCurrencyMismatch):
+			v.AddError("book_id", "book is priced in a different currency than the cart")
+			b.validationError(w, r, v.Errors)
+		case errors.Is(err, model.ErrInvalidQuantity):
+			v.AddError("quantity", "cannot be more than 100 for a single book")
+			b.validationError(w, r, v.Errors)
+		default:
+			b.serverError(w, r, err)
+		}
+		return
+	}
+
+	cart, err := b.models.Carts.GetForUser(r.Context(), user.ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := addCartItemResponse{
+		Code: http.StatusOK,
+		Cart: cart,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type updateCartItemResponse struct {
+	Code int         `json:"code"`
+	Cart *model.Cart `json:"cart"`
+}
+
+func (b *Bookshop) updateCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readInt64Param(r, "book_id")
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	var input struct {
+		Quantity int `json:"quantity"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if model.ValidateCartItemQuantity(v, input.Quantity); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user := b.contextGetUser(r)
+
+	if err := b.models.Carts.UpdateItem(r.Context(), user.ID, bookID, input.Quantity); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	cart, err := b.models.Carts.GetForUser(r.Context(), user.ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := updateCartItemResponse{
+		Code: http.StatusOK,
+		Cart: cart,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type removeCartItemResponse struct {
+	Code int         `json:"code"`
+	Cart *model.Cart `json:"cart"`
+}
+
+func (b *Bookshop) removeCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readInt64Param(r, "book_id")
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	user := b.contextGetUser(r)
+
+	if err := b.models.Carts.RemoveItem(r.Context(), user.ID, bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	cart, err := b.models.Carts.GetForUser(r.Context(), user.ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := removeCartItemResponse{
+		Code: http.StatusOK,
+		Cart: cart,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,203 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestShowCartHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name         string
+		headers      http.Header
+		wantCode     int
+		wantSubtotal string
+	}{
+		{
+			name:     "anonymous user",
+			headers:  nil,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "empty cart",
+			headers:      staffHeaders(),
+			wantCode:     http.StatusOK,
+			wantSubtotal: "0.00",
+		},
+		{
+			name:         "cart with items",
+			headers:      customerHeaders(),
+			wantCode:     http.StatusOK,
+			wantSubtotal: "20.00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodGet, "/api/v1/cart", "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res showCartResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Cart.Subtotal.String() != tc.wantSubtotal {
+				t.Errorf("expected cart subtotal to be %s; got %s", tc.wantSubtotal, res.Cart.Subtotal)
+			}
+		})
+	}
+}
+
+func TestAddCartItemHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "missing book",
+			headers:  staffHeaders(),
+			body:     `{"quantity": 1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "book not found",
+			headers:  staffHeaders(),
+			body:     `{"book_id": 2}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid quantity",
+			headers:  staffHeaders(),
+			body:     `{"book_id": 1, "quantity": 0}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "quantity above limit",
+			headers:  customerHeaders(),
+			body:     `{"book_id": 1, "quantity": 99}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  customerHeaders(),
+			body:     `{"book_id": 1, "quantity": 3}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodPost, "/api/v1/cart/items", tc.body, tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestUpdateCartItemHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "item not in cart",
+			path:     "/api/v1/cart/items/2",
+			body:     `{"quantity": 1}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid quantity",
+			path:     "/api/v1/cart/items/1",
+			body:     `{"quantity": 101}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			path:     "/api/v1/cart/items/1",
+			body:     `{"quantity": 5}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodPatch, tc.path, tc.body, customerHeaders())
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestRemoveCartItemHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{
+			name:     "item not in cart",
+			path:     "/api/v1/cart/items/2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			path:     "/api/v1/cart/items/1",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodDelete, tc.path, "", customerHeaders())
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
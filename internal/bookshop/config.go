@@ -1,14 +1,70 @@
 package bookshop
 
-import "fmt"
+import (
+	"fmt"
+	"net/netip"
+	"time"
+)
 
 type Config struct {
-	Port int
+	Port           int
+	QueryTimeout   time.Duration
+	LimiterEnabled bool
+	LimiterRPS     float64
+	LimiterBurst   int
+	TrustedProxies []string
+	TrustedOrigins []string
+	BannedWords    []string
+
+	trustedProxies []netip.Prefix
 }
 
 func (c *Config) parse() (*Config, error) {
 	if c.Port < 3000 || c.Port > 9999 {
 		return nil, fmt.Errorf("invalid bookshop '%d' port number", c.Port)
 	}
+
+	if c.QueryTimeout <= 0 {
+		c.QueryTimeout = time.Second * 5
+	}
+
+	if c.LimiterRPS <= 0 {
+		c.LimiterRPS = 2
+	}
+
+	if c.LimiterBurst < 1 {
+		c.LimiterBurst = 4
+	}
+
+	c.trustedProxies = make([]netip.Prefix, 0, len(c.TrustedProxies))
+
+	for _, proxy := range c.TrustedProxies {
+		prefix, err := parseTrustedProxy(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookshop '%s' trusted proxy", proxy)
+		}
+		c.trustedProxies = append(c.trustedProxies, prefix)
+	}
+
 	return c, nil
 }
+
+func (c *Config) isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(proxy); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -1,6 +1,9 @@
 package bookshop
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestConfigParse(t *testing.T) {
 	t.Parallel()
@@ -16,8 +19,40 @@ func TestConfigParse(t *testing.T) {
 
 	config.Port = 8000
 
-	_, err = config.parse()
+	cfg, err := config.parse()
 	if err != nil {
 		t.Fatalf("expected error to be nil; got %v", err)
 	}
+
+	queryTimeout := time.Second * 5
+	if cfg.QueryTimeout != queryTimeout {
+		t.Errorf("expected query-timeout to be %v; got %v", queryTimeout, cfg.QueryTimeout)
+	}
+}
+
+func TestConfigParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		Port:           8000,
+		TrustedProxies: []string{"10.0.0.0/8", "invalid"},
+	}
+
+	_, err := config.parse()
+	wantErr := "invalid bookshop 'invalid' trusted proxy"
+
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("expected error to be %v; got %v", wantErr, err)
+	}
+
+	config.TrustedProxies = []string{"10.0.0.0/8", "127.0.0.1"}
+
+	cfg, err := config.parse()
+	if err != nil {
+		t.Fatalf("expected error to be nil; got %v", err)
+	}
+
+	if len(cfg.trustedProxies) != 2 {
+		t.Errorf("expected trusted proxies length to be %d; got %d", 2, len(cfg.trustedProxies))
+	}
 }
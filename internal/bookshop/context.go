@@ -0,0 +1,43 @@
+package bookshop
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+// contextKey is the bookshop string type used to avoid collisions.
+type contextKey string
+
+const (
+	// userContextKey identifies the user value stored in the request context.
+	userContextKey = contextKey("user")
+	// apiKeyContextKey identifies the api key value stored in the request context.
+	apiKeyContextKey = contextKey("api_key")
+)
+
+func (b *Bookshop) contextSetUser(r *http.Request, user *model.User) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(ctx)
+}
+
+func (b *Bookshop) contextGetUser(r *http.Request) *model.User {
+	user, ok := r.Context().Value(userContextKey).(*model.User)
+	if !ok {
+		panic("missing user value in request context")
+	}
+	return user
+}
+
+func (b *Bookshop) contextSetAPIKey(r *http.Request, key *model.APIKey) *http.Request {
+	ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+	return r.WithContext(ctx)
+}
+
+// contextGetAPIKey returns nil when the request was not authenticated with an
+// api key, since most requests are not.
+func (b *Bookshop) contextGetAPIKey(r *http.Request) *model.APIKey {
+	key, _ := r.Context().Value(apiKeyContextKey).(*model.APIKey)
+	return key
+}
@@ -1,10 +1,13 @@
 package bookshop
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/logging"
 	"github.com/dlbarduzzi/bookshop/internal/validator"
 )
 
@@ -32,7 +35,7 @@ type serverErrorResponse struct {
 }
 
 func (b *Bookshop) serverError(w http.ResponseWriter, r *http.Request, e error) {
-	b.logger.Error(
+	logging.LoggerFromContext(r.Context()).Error(
 		e.Error(),
 		slog.String("path", r.URL.Path),
 		slog.String("method", r.Method),
@@ -48,3 +51,191 @@ func (b *Bookshop) serverError(w http.ResponseWriter, r *http.Request, e error)
 		return
 	}
 }
+
+type notFoundResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) notFound(w http.ResponseWriter, r *http.Request) {
+	res := notFoundResponse{
+		Code:    http.StatusNotFound,
+		Message: "The requested resource could not be found.",
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type editConflictResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) editConflict(w http.ResponseWriter, r *http.Request) {
+	res := editConflictResponse{
+		Code:    http.StatusConflict,
+		Message: "Unable to update the record due to an edit conflict, please try again.",
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type insufficientStockResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) insufficientStock(w http.ResponseWriter, r *http.Request) {
+	res := insufficientStockResponse{
+		Code:    http.StatusConflict,
+		Message: "There are not enough copies in stock for one or more books in your cart.",
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type invalidOrderStatusResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) invalidOrderStatus(w http.ResponseWriter, r *http.Request, message string) {
+	res := invalidOrderStatusResponse{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type paymentDeclinedResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+func (b *Bookshop) paymentDeclined(w http.ResponseWriter, r *http.Request, reason string) {
+	res := paymentDeclinedResponse{
+		Code:    http.StatusPaymentRequired,
+		Message: "The payment was declined, please try another payment method.",
+		Reason:  reason,
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type rateLimitExceededResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) rateLimitExceeded(w http.ResponseWriter, r *http.Request, retryAfter int) {
+	res := rateLimitExceededResponse{
+		Code:    http.StatusTooManyRequests,
+		Message: "Rate limit exceeded.",
+	}
+
+	headers := make(http.Header)
+	headers.Set("Retry-After", strconv.Itoa(retryAfter))
+
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type methodNotAllowedResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) methodNotAllowed(w http.ResponseWriter, r *http.Request, allow string) {
+	res := methodNotAllowedResponse{
+		Code:    http.StatusMethodNotAllowed,
+		Message: fmt.Sprintf("The %s method is not supported for this resource.", r.Method),
+	}
+
+	headers := make(http.Header)
+	headers.Set("Allow", allow)
+
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type unauthorizedResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) unauthorized(w http.ResponseWriter, r *http.Request, message string, headers http.Header) {
+	res := unauthorizedResponse{
+		Code:    http.StatusUnauthorized,
+		Message: message,
+	}
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+func (b *Bookshop) invalidCredentials(w http.ResponseWriter, r *http.Request) {
+	b.unauthorized(w, r, "Invalid authentication credentials.", nil)
+}
+
+func (b *Bookshop) invalidAuthenticationToken(w http.ResponseWriter, r *http.Request) {
+	headers := make(http.Header)
+	headers.Set("WWW-Authenticate", "Bearer")
+	b.unauthorized(w, r, "Invalid or missing authentication token.", headers)
+}
+
+func (b *Bookshop) invalidAPIKey(w http.ResponseWriter, r *http.Request) {
+	headers := make(http.Header)
+	headers.Set("WWW-Authenticate", "ApiKey")
+	b.unauthorized(w, r, "Invalid or revoked api key.", headers)
+}
+
+func (b *Bookshop) authenticationRequired(w http.ResponseWriter, r *http.Request) {
+	headers := make(http.Header)
+	headers.Set("WWW-Authenticate", "Bearer")
+	b.unauthorized(w, r, "You must be authenticated to access this resource.", headers)
+}
+
+type forbiddenResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) forbidden(w http.ResponseWriter, r *http.Request, message string) {
+	res := forbiddenResponse{
+		Code:    http.StatusForbidden,
+		Message: message,
+	}
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+func (b *Bookshop) inactiveAccount(w http.ResponseWriter, r *http.Request) {
+	b.forbidden(w, r, "Your user account must be activated to access this resource.")
+}
+
+func (b *Bookshop) twoFactorSessionRequired(w http.ResponseWriter, r *http.Request) {
+	b.forbidden(w, r, "You must sign in with two-factor authentication to access this resource.")
+}
+
+func (b *Bookshop) notPermitted(w http.ResponseWriter, r *http.Request) {
+	b.forbidden(w, r, "Your user account doesn't have the necessary permissions to access this resource.")
+}
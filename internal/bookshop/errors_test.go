@@ -14,10 +14,7 @@ func TestServerError(t *testing.T) {
 
 	app := newTestBookshop(t)
 
-	r, err := http.NewRequest(http.MethodGet, "/", nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+	r := newTestRequest(t, app, http.MethodGet, "/")
 
 	w := httptest.NewRecorder()
 	app.serverError(w, r, fmt.Errorf("test server error"))
@@ -43,3 +40,25 @@ func TestServerError(t *testing.T) {
 		t.Errorf("expected error message to be %s; got %s", wantMessage, res.Message)
 	}
 }
+
+func TestEditConflict(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	r := newTestRequest(t, app, http.MethodPatch, "/")
+
+	w := httptest.NewRecorder()
+	app.editConflict(w, r)
+
+	var res editConflictResponse
+
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	wantCode := http.StatusConflict
+	if res.Code != wantCode {
+		t.Errorf("expected status code to be %d; got %d", wantCode, res.Code)
+	}
+}
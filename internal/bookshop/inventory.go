@@ -0,0 +1,180 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+type showInventoryResponse struct {
+	Code      int              `json:"code"`
+	Inventory *model.Inventory `json:"inventory"`
+}
+
+func (b *Bookshop) showInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	if _, err := b.models.Books.Get(r.Context(), bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	inventory, err := b.models.Inventory.Get(r.Context(), bookID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := showInventoryResponse{
+		Code:      http.StatusOK,
+		Inventory: inventory,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type adjustInventoryResponse struct {
+	Code      int                      `json:"code"`
+	Inventory *model.Inventory         `json:"inventory"`
+	Movement  *model.InventoryMovement `json:"movement"`
+}
+
+func (b *Bookshop) adjustInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	if _, err := b.models.Books.Get(r.Context(), bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	var input struct {
+		Quantity int    `json:"quantity"`
+		Reason   string `json:"reason"`
+		Note     string `json:"note"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	staffID := b.contextGetUser(r).ID
+
+	movement := &model.InventoryMovement{
+		BookID:    bookID,
+		Quantity:  input.Quantity,
+		Reason:    input.Reason,
+		Note:      input.Note,
+		CreatedBy: &staffID,
+	}
+
+	if model.ValidateInventoryAdjustment(v, movement); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	inventory, err := b.models.Inventory.Adjust(r.Context(), movement)
+	if err != nil {
+		if errors.Is(err, model.ErrInsufficientStock) {
+			v.AddError("quantity", "cannot leave fewer copies on hand than reserved")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := adjustInventoryResponse{
+		Code:      http.StatusOK,
+		Inventory: inventory,
+		Movement:  movement,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type listInventoryMovementsResponse struct {
+	Code      int                        `json:"code"`
+	Movements []*model.InventoryMovement `json:"movements"`
+	Metadata  model.Metadata             `json:"metadata"`
+}
+
+func (b *Bookshop) listInventoryMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	var input struct {
+		model.Filters
+	}
+
+	q := r.URL.Query()
+	v := validator.NewValidator()
+
+	input.Filters.Page = b.readInt(q, "page", 1, v)
+	input.Filters.PageSize = b.readInt(q, "page_size", 10, v)
+
+	input.Filters.Sort = b.readString(q, "sort", "-created_at")
+	input.Filters.SortSafeList = []string{"id", "created_at", "-id", "-created_at"}
+
+	if input.Filters.Validate(v); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if _, err := b.models.Books.Get(r.Context(), bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	movements, metadata, err := b.models.Inventory.GetMovements(r.Context(), bookID, input.Filters)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := listInventoryMovementsResponse{
+		Code:      http.StatusOK,
+		Movements: movements,
+		Metadata:  metadata,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,149 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestShowInventoryHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "customer user",
+			path:     "/api/v1/books/1/inventory",
+			headers:  customerHeaders(),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "book not found",
+			path:     "/api/v1/books/2/inventory",
+			headers:  staffHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			path:     "/api/v1/books/1/inventory",
+			headers:  staffHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodGet, tc.path, "", tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestAdjustInventoryHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantOnHand int
+	}{
+		{
+			name:     "invalid reason",
+			body:     `{"quantity": 1, "reason": "sale"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "negative received quantity",
+			body:     `{"quantity": -1, "reason": "received"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "more damaged than on hand",
+			body:     `{"quantity": -6, "reason": "damaged"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:       "received",
+			body:       `{"quantity": 10, "reason": "received", "note": "Supplier delivery"}`,
+			wantCode:   http.StatusOK,
+			wantOnHand: 15,
+		},
+		{
+			name:       "correction",
+			body:       `{"quantity": -2, "reason": "correction"}`,
+			wantCode:   http.StatusOK,
+			wantOnHand: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			path := "/api/v1/books/1/inventory/adjustments"
+
+			code, body := srv.request(t, http.MethodPost, path, tc.body, staffHeaders())
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res adjustInventoryResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Inventory.OnHand != tc.wantOnHand {
+				t.Errorf("expected on hand quantity to be %d; got %d", tc.wantOnHand, res.Inventory.OnHand)
+			}
+
+			if res.Movement.CreatedBy == nil {
+				t.Error("expected movement to record the staff user")
+			}
+		})
+	}
+}
+
+func TestListInventoryMovementsHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	code, body := srv.request(t, http.MethodGet, "/api/v1/books/1/inventory/movements", "", staffHeaders())
+	if code != http.StatusOK {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusOK, code)
+	}
+
+	var res listInventoryMovementsResponse
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Movements) != 1 {
+		t.Errorf("expected 1 inventory movement; got %d", len(res.Movements))
+	}
+}
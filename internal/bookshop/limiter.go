@@ -0,0 +1,78 @@
+package bookshop
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type limiterClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type limiter struct {
+	mu          sync.Mutex
+	rps         rate.Limit
+	burst       int
+	clients     map[string]*limiterClient
+	lastCleanup time.Time
+}
+
+func newLimiter(rps float64, burst int) *limiter {
+	return &limiter{
+		rps:         rate.Limit(rps),
+		burst:       burst,
+		clients:     make(map[string]*limiterClient),
+		lastCleanup: time.Now(),
+	}
+}
+
+// allow reports whether a request from the given key is allowed and, when it
+// is not, how long the client should wait before trying again.
+func (l *limiter) allow(key string) (bool, time.Duration) {
+	return l.allowWithLimit(key, float64(l.rps), l.burst)
+}
+
+// allowWithLimit is like allow, but uses the given rate and burst instead of
+// the limiter defaults, for clients that carry their own limits.
+func (l *limiter) allowWithLimit(key string, rps float64, burst int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(l.lastCleanup) > time.Minute {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) > time.Minute*3 {
+				delete(l.clients, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	c, exists := l.clients[key]
+	if !exists {
+		c = &limiterClient{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+		l.clients[key] = c
+	}
+
+	if c.limiter.Limit() != rate.Limit(rps) {
+		c.limiter.SetLimitAt(now, rate.Limit(rps))
+	}
+
+	if c.limiter.Burst() != burst {
+		c.limiter.SetBurstAt(now, burst)
+	}
+
+	c.lastSeen = now
+
+	reservation := c.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
@@ -0,0 +1,44 @@
+package bookshop
+
+import "testing"
+
+func TestLimiterAllow(t *testing.T) {
+	t.Parallel()
+
+	l := newLimiter(1, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("foo"); !ok {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+
+	ok, retryAfter := l.allow("foo")
+	if ok {
+		t.Fatal("expected request to be rate limited")
+	}
+
+	if retryAfter <= 0 {
+		t.Errorf("expected retry-after to be greater than 0; got %v", retryAfter)
+	}
+
+	if ok, _ := l.allow("bar"); !ok {
+		t.Error("expected request from a different client to be allowed")
+	}
+}
+
+func TestLimiterAllowWithLimit(t *testing.T) {
+	t.Parallel()
+
+	l := newLimiter(1, 1)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allowWithLimit("foo", 1, 3); !ok {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+
+	if ok, _ := l.allowWithLimit("foo", 1, 3); ok {
+		t.Error("expected request to be rate limited")
+	}
+}
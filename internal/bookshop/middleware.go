@@ -0,0 +1,368 @@
+package bookshop
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/logging"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func (b *Bookshop) recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				w.Header().Set("Connection", "close")
+				logging.LoggerFromContext(r.Context()).Error(
+					"recovered from panic",
+					slog.String("panic", fmt.Sprintf("%v", err)),
+					slog.String("stack", string(debug.Stack())),
+				)
+				b.serverError(w, r, fmt.Errorf("%v", err))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+var requestIDRX = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+func (b *Bookshop) logRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get("X-Request-ID")
+		if !requestIDRX.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", requestID)
+
+		logger := b.logger.With(slog.String("request_id", requestID))
+		r = r.WithContext(logging.LoggerWithContext(r.Context(), logger))
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		logger.Info(
+			"request completed",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rw.status),
+			slog.Int("bytes", rw.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_ip", b.clientIP(r)),
+		)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the address of the client that made the request. The
+// X-Forwarded-For header is only honoured when the request comes from a trusted
+// proxy, in which case the right-most address that is not a trusted proxy wins.
+func (b *Bookshop) clientIP(r *http.Request) string {
+	ip := remoteIP(r)
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !b.config.isTrustedProxy(addr) {
+		return ip
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		ip = addr.String()
+		if !b.config.isTrustedProxy(addr) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (b *Bookshop) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.config.LimiterEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Requests authenticated with an api key are limited per key by
+		// authenticate, so that partners behind a shared address do not exhaust
+		// each other. Failed api key attempts are limited per address there.
+		if isAPIKeyRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, retryAfter := b.limiter.allow(b.clientIP(r)); !ok {
+			b.rateLimitExceeded(w, r, retryAfterSeconds(retryAfter))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (b *Bookshop) enableCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+
+		origin := r.Header.Get("Origin")
+
+		if origin != "" && slices.Contains(b.config.TrustedOrigins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, GET, POST, PUT, PATCH, DELETE")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-API-Key, X-Request-ID")
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// isAPIKeyRequest reports whether authenticate takes the api key path for the
+// request. A bearer token takes precedence, so requests carrying both are
+// authenticated, and rate limited, as users.
+func isAPIKeyRequest(r *http.Request) bool {
+	return r.Header.Get("Authorization") == "" && r.Header.Get("X-API-Key") != ""
+}
+
+func (b *Bookshop) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+		w.Header().Add("Vary", "X-API-Key")
+
+		if isAPIKeyRequest(r) {
+			b.authenticateAPIKey(next, w, r, r.Header.Get("X-API-Key"))
+			return
+		}
+
+		authorizationHeader := r.Header.Get("Authorization")
+
+		if authorizationHeader == "" {
+			r = b.contextSetUser(r, model.AnonymousUser)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(authorizationHeader, "Bearer ")
+		if !ok {
+			b.invalidAuthenticationToken(w, r)
+			return
+		}
+
+		v := validator.NewValidator()
+
+		if model.ValidateTokenPlaintext(v, token); !v.IsValid() {
+			b.invalidAuthenticationToken(w, r)
+			return
+		}
+
+		user, err := b.models.Users.GetForToken(r.Context(), model.ScopeAuthentication, token)
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				b.invalidAuthenticationToken(w, r)
+				return
+			}
+			b.serverError(w, r, err)
+			return
+		}
+
+		r = b.contextSetUser(r, user)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (b *Bookshop) authenticateAPIKey(next http.Handler, w http.ResponseWriter, r *http.Request, plaintext string) {
+	v := validator.NewValidator()
+
+	var key *model.APIKey
+	var err error
+
+	if model.ValidateAPIKeyPlaintext(v, plaintext); v.IsValid() {
+		key, err = b.models.APIKeys.GetForPlaintext(r.Context(), plaintext)
+		if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+			b.serverError(w, r, err)
+			return
+		}
+	}
+
+	if key == nil {
+		// Failed attempts count against the client address, so that guessing
+		// keys is throttled like any other anonymous request.
+		if b.config.LimiterEnabled {
+			if ok, retryAfter := b.limiter.allow(b.clientIP(r)); !ok {
+				b.rateLimitExceeded(w, r, retryAfterSeconds(retryAfter))
+				return
+			}
+		}
+		b.invalidAPIKey(w, r)
+		return
+	}
+
+	if b.config.LimiterEnabled {
+		limiterKey := fmt.Sprintf("api_key:%d", key.ID)
+		if ok, retryAfter := b.limiter.allowWithLimit(limiterKey, key.RPS, key.Burst); !ok {
+			b.rateLimitExceeded(w, r, retryAfterSeconds(retryAfter))
+			return
+		}
+	}
+
+	r = b.contextSetAPIKey(r, key)
+	r = b.contextSetUser(r, model.AnonymousUser)
+
+	next.ServeHTTP(w, r)
+}
+
+func (b *Bookshop) requireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if b.contextGetUser(r).IsAnonymous() {
+			b.authenticationRequired(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+func (b *Bookshop) requireActivatedUser(next http.HandlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !b.contextGetUser(r).Activated {
+			b.inactiveAccount(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return b.requireAuthenticatedUser(fn)
+}
+
+// requirePermission checks the scopes of the api key when the request carries
+// one, and the permissions of the authenticated user otherwise.
+func (b *Bookshop) requirePermission(code string, next http.HandlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		user := b.contextGetUser(r)
+
+		permissions, err := b.models.Permissions.GetAllForUser(r.Context(), user.ID)
+		if err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+
+		if !permissions.Include(code) {
+			b.notPermitted(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	userFn := b.requireActivatedUser(fn)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if key := b.contextGetAPIKey(r); key != nil {
+			if !key.Scopes.Include(code) {
+				b.notPermitted(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		userFn(w, r)
+	}
+}
+
+// requireAPIKeyScope checks the scopes of the api key when the request carries
+// one, and lets every other request through. It guards public routes, which
+// users and anonymous clients can reach but partners only with the scope.
+func (b *Bookshop) requireAPIKeyScope(code string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if key := b.contextGetAPIKey(r); key != nil && !key.Scopes.Include(code) {
+			b.notPermitted(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requireTwoFactor must be wrapped by requirePermission or requireActivatedUser,
+// since it expects the request context to hold an authenticated user. It checks
+// that the session itself passed two-factor authentication, not just that the
+// account has it enabled, so a stolen password alone is never enough.
+func (b *Bookshop) requireTwoFactor(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !b.contextGetUser(r).TwoFactor {
+			b.twoFactorSessionRequired(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,541 @@
+package bookshop
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	})
+
+	r := newTestRequest(t, app, http.MethodGet, "/")
+
+	w := httptest.NewRecorder()
+	app.recoverPanic(next).ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code to be %d; got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	connection := w.Header().Get("Connection")
+	if connection != "close" {
+		t.Errorf("expected connection header to be %s; got %s", "close", connection)
+	}
+
+	var res serverErrorResponse
+
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	wantMessage := "Internal server error."
+	if res.Message != wantMessage {
+		t.Errorf("expected error message to be %s; got %s", wantMessage, res.Message)
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+	}{
+		{
+			name:          "propagated",
+			requestID:     "test-request-id",
+			wantRequestID: "test-request-id",
+		},
+		{
+			name:      "generated",
+			requestID: "",
+		},
+		{
+			name:      "invalid",
+			requestID: "invalid request id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			app := newTestBookshop(t)
+			app.logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte("test"))
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.Header.Set("X-Request-ID", tc.requestID)
+
+			w := httptest.NewRecorder()
+			app.logRequest(next).ServeHTTP(w, r)
+
+			requestID := w.Header().Get("X-Request-ID")
+			if requestID == "" {
+				t.Fatal("expected request id header not to be empty")
+			}
+
+			if tc.wantRequestID != "" && requestID != tc.wantRequestID {
+				t.Errorf("expected request id to be %s; got %s", tc.wantRequestID, requestID)
+			}
+
+			var entry struct {
+				RequestID string `json:"request_id"`
+				Method    string `json:"method"`
+				Path      string `json:"path"`
+				Status    int    `json:"status"`
+				Bytes     int    `json:"bytes"`
+			}
+
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+
+			if entry.RequestID != requestID {
+				t.Errorf("expected logged request id to be %s; got %s", requestID, entry.RequestID)
+			}
+
+			if entry.Method != http.MethodGet || entry.Path != "/test" {
+				t.Errorf("expected logged request to be GET /test; got %s %s", entry.Method, entry.Path)
+			}
+
+			if entry.Status != http.StatusTeapot {
+				t.Errorf("expected logged status to be %d; got %d", http.StatusTeapot, entry.Status)
+			}
+
+			if entry.Bytes != 4 {
+				t.Errorf("expected logged bytes to be %d; got %d", 4, entry.Bytes)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+
+	if _, err := app.config.parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		wantIP     string
+	}{
+		{
+			name:       "untrusted remote address",
+			remoteAddr: "203.0.113.1:1234",
+			forwarded:  "198.51.100.1",
+			wantIP:     "203.0.113.1",
+		},
+		{
+			name:       "trusted remote address",
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  "198.51.100.1",
+			wantIP:     "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy chain",
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  "203.0.113.9, 198.51.100.1, 192.168.1.1",
+			wantIP:     "198.51.100.1",
+		},
+		{
+			name:       "trusted remote address without header",
+			remoteAddr: "10.0.0.1:1234",
+			wantIP:     "10.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+
+			if tc.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+
+			ip := app.clientIP(r)
+			if ip != tc.wantIP {
+				t.Errorf("expected client ip to be %s; got %s", tc.wantIP, ip)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.LimiterEnabled = true
+	app.limiter = newLimiter(1, 1)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := app.rateLimit(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTestRequest(t, app, http.MethodGet, "/"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newTestRequest(t, app, http.MethodGet, "/"))
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusTooManyRequests, w.Code)
+	}
+
+	retryAfter := w.Header().Get("Retry-After")
+	if retryAfter != "1" {
+		t.Errorf("expected retry-after header to be %s; got %s", "1", retryAfter)
+	}
+
+	var res rateLimitExceededResponse
+
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	wantMessage := "Rate limit exceeded."
+	if res.Message != wantMessage {
+		t.Errorf("expected error message to be %s; got %s", wantMessage, res.Message)
+	}
+}
+
+func TestEnableCORS(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.TrustedOrigins = []string{"http://localhost:3000"}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	testCases := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantCode    int
+		wantOrigin  string
+		wantMethods bool
+	}{
+		{
+			name:     "no origin",
+			method:   http.MethodGet,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "untrusted origin",
+			method:   http.MethodGet,
+			origin:   "http://example.com",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:       "trusted origin",
+			method:     http.MethodGet,
+			origin:     "http://localhost:3000",
+			wantCode:   http.StatusNoContent,
+			wantOrigin: "http://localhost:3000",
+		},
+		{
+			name:        "trusted preflight",
+			method:      http.MethodOptions,
+			origin:      "http://localhost:3000",
+			preflight:   true,
+			wantCode:    http.StatusOK,
+			wantOrigin:  "http://localhost:3000",
+			wantMethods: true,
+		},
+		{
+			name:      "untrusted preflight",
+			method:    http.MethodOptions,
+			origin:    "http://example.com",
+			preflight: true,
+			wantCode:  http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newTestRequest(t, app, tc.method, "/")
+
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+
+			if tc.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+			}
+
+			w := httptest.NewRecorder()
+			app.enableCORS(next).ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, w.Code)
+			}
+
+			if !slices.Contains(w.Header().Values("Vary"), "Origin") {
+				t.Error("expected vary header to contain origin")
+			}
+
+			origin := w.Header().Get("Access-Control-Allow-Origin")
+			if origin != tc.wantOrigin {
+				t.Errorf("expected allow-origin header to be %q; got %q", tc.wantOrigin, origin)
+			}
+
+			methods := w.Header().Get("Access-Control-Allow-Methods")
+			if (methods != "") != tc.wantMethods {
+				t.Errorf("expected allow-methods header presence to be %t; got %q", tc.wantMethods, methods)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantAnonymous bool
+	}{
+		{
+			name:          "anonymous",
+			wantCode:      http.StatusOK,
+			wantAnonymous: true,
+		},
+		{
+			name:          "malformed token",
+			authorization: "Bearer invalid",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:          "authenticated",
+			authorization: "Bearer " + mocks.AuthenticationToken,
+			wantCode:      http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var anonymous bool
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				anonymous = app.contextGetUser(r).IsAnonymous()
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := newTestRequest(t, app, http.MethodGet, "/")
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			app.authenticate(next).ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, w.Code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				if w.Header().Get("WWW-Authenticate") != "Bearer" {
+					t.Error("expected www-authenticate header to be Bearer")
+				}
+				return
+			}
+
+			if anonymous != tc.wantAnonymous {
+				t.Errorf("expected anonymous user to be %t; got %t", tc.wantAnonymous, anonymous)
+			}
+		})
+	}
+}
+
+func TestRequireActivatedUser(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name     string
+		user     *model.User
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			user:     model.AnonymousUser,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "inactive user",
+			user:     &model.User{ID: 1, Activated: false},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "activated user",
+			user:     &model.User{ID: 1, Activated: true},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := app.contextSetUser(newTestRequest(t, app, http.MethodGet, "/"), tc.user)
+
+			w := httptest.NewRecorder()
+			app.requireActivatedUser(next).ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireAPIKeyScope(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name     string
+		key      *model.APIKey
+		wantCode int
+	}{
+		{
+			name:     "no api key",
+			key:      nil,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "missing scope",
+			key:      &model.APIKey{ID: 1, Scopes: model.Permissions{}},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "with scope",
+			key:      &model.APIKey{ID: 1, Scopes: model.Permissions{model.PermissionBooksRead}},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newTestRequest(t, app, http.MethodGet, "/")
+			if tc.key != nil {
+				r = app.contextSetAPIKey(r, tc.key)
+			}
+
+			w := httptest.NewRecorder()
+			app.requireAPIKeyScope(model.PermissionBooksRead, next).ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name      string
+		userID    int64
+		twoFactor bool
+		wantCode  int
+	}{
+		{
+			name:     "not enrolled",
+			userID:   2,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "enabled with password only session",
+			userID:   1,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:      "two-factor session",
+			userID:    1,
+			twoFactor: true,
+			wantCode:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := &model.User{ID: tc.userID, Activated: true, TwoFactor: tc.twoFactor}
+			r := app.contextSetUser(newTestRequest(t, app, http.MethodGet, "/"), user)
+
+			w := httptest.NewRecorder()
+			app.requireTwoFactor(next).ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, w.Code)
+			}
+		})
+	}
+}
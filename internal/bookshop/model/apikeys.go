@@ -0,0 +1,233 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	apiKeyPrefix       = "bsk_"
+	apiKeyLength       = 36
+	apiKeyPrefixLength = 12
+)
+
+type APIKey struct {
+	ID         int64       `json:"id"`
+	Name       string      `json:"name"`
+	Hash       []byte      `json:"-"`
+	Prefix     string      `json:"prefix"`
+	Scopes     Permissions `json:"scopes"`
+	RPS        float64     `json:"rps"`
+	Burst      int         `json:"burst"`
+	CreatedBy  int64       `json:"-"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsedAt *time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time  `json:"revoked_at"`
+}
+
+type APIKeyModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type APIKeyStore interface {
+	Insert(context.Context, *APIKey) error
+	GetAll(context.Context) ([]*APIKey, error)
+	GetForPlaintext(context.Context, string) (*APIKey, error)
+	Revoke(context.Context, int64) error
+}
+
+// GenerateAPIKey returns a new plaintext key and sets the hash and prefix on
+// the given key. The plaintext is never stored, so it can only be shown once.
+func GenerateAPIKey(key *APIKey) (string, error) {
+	randomBytes := make([]byte, 20)
+
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
+	plaintext := apiKeyPrefix + strings.ToLower(encoded)
+
+	key.Hash = HashToken(plaintext)
+	key.Prefix = plaintext[:apiKeyPrefixLength]
+
+	return plaintext, nil
+}
+
+func ValidateAPIKey(v *validator.Validator, key *APIKey) {
+	if strings.TrimSpace(key.Name) == "" {
+		v.AddError("name", "must be provided")
+	} else if len(key.Name) > 200 {
+		v.AddError("name", "cannot be more than 200 bytes long")
+	}
+
+	if len(key.Scopes) < 1 {
+		v.AddError("scopes", "must contain at least 1 scope")
+	} else if !validator.Unique(key.Scopes) {
+		v.AddError("scopes", "cannot contain duplicate values")
+	}
+
+	for _, scope := range key.Scopes {
+		if !validator.ValueInList(scope, PermissionBooksRead) {
+			v.AddError("scopes", "contains an invalid scope")
+			break
+		}
+	}
+
+	if !validator.ValueInRange(key.RPS, 0.1, 100) {
+		v.AddError("rps", "must be between 0.1 and 100")
+	}
+
+	if !validator.ValueInRange(key.Burst, 1, 1000) {
+		v.AddError("burst", "must be between 1 and 1000")
+	}
+}
+
+func ValidateAPIKeyPlaintext(v *validator.Validator, plaintext string) {
+	if len(plaintext) != apiKeyLength || !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		v.AddError("api_key", "must be a valid api key")
+	}
+}
+
+func (m APIKeyModel) Insert(ctx context.Context, key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, hash, prefix, scopes, rps, burst, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		key.Name,
+		key.Hash,
+		key.Prefix,
+		pq.Array([]string(key.Scopes)),
+		key.RPS,
+		key.Burst,
+		key.CreatedBy,
+	}
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (m APIKeyModel) GetAll(ctx context.Context) ([]*APIKey, error) {
+	query := `
+		SELECT id, name, prefix, scopes, rps, burst, COALESCE(created_by, 0),
+            created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []*APIKey{}
+
+	for rows.Next() {
+		var key APIKey
+
+		err := rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.Prefix,
+			pq.Array((*[]string)(&key.Scopes)),
+			&key.RPS,
+			&key.Burst,
+			&key.CreatedBy,
+			&key.CreatedAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetForPlaintext looks up an active key and records its usage in the same
+// statement, so that authenticating a request costs a single round trip.
+func (m APIKeyModel) GetForPlaintext(ctx context.Context, plaintext string) (*APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE hash = $1 AND revoked_at IS NULL
+		RETURNING id, name, prefix, scopes, rps, burst, COALESCE(created_by, 0),
+            created_at, last_used_at, revoked_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var key APIKey
+
+	err := m.DB.QueryRowContext(ctx, query, HashToken(plaintext)).Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array((*[]string)(&key.Scopes)),
+		&key.RPS,
+		&key.Burst,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (m APIKeyModel) Revoke(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	var key APIKey
+
+	plaintext, err := GenerateAPIKey(&key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := validator.NewValidator()
+
+	if ValidateAPIKeyPlaintext(v, plaintext); !v.IsValid() {
+		t.Errorf("expected generated key to be valid; got %v", v.Errors)
+	}
+
+	if !strings.HasPrefix(plaintext, key.Prefix) {
+		t.Errorf("expected key %s to start with prefix %s", plaintext, key.Prefix)
+	}
+
+	if string(key.Hash) != string(HashToken(plaintext)) {
+		t.Error("expected key hash to match plaintext hash")
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		key     APIKey
+		field   string
+		wantErr string
+	}{
+		{
+			name:    "empty_name",
+			key:     APIKey{Scopes: Permissions{PermissionBooksRead}, RPS: 1, Burst: 1},
+			field:   "name",
+			wantErr: "must be provided",
+		},
+		{
+			name:    "invalid_scope",
+			key:     APIKey{Name: "Partner", Scopes: Permissions{"foo"}, RPS: 1, Burst: 1},
+			field:   "scopes",
+			wantErr: "contains an invalid scope",
+		},
+		{
+			name:    "invalid_rps",
+			key:     APIKey{Name: "Partner", Scopes: Permissions{PermissionBooksRead}, RPS: 500, Burst: 1},
+			field:   "rps",
+			wantErr: "must be between 0.1 and 100",
+		},
+		{
+			name: "success",
+			key:  APIKey{Name: "Partner", Scopes: Permissions{PermissionBooksRead}, RPS: 1, Burst: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validator.NewValidator()
+			ValidateAPIKey(v, &tc.key)
+
+			if tc.wantErr == "" {
+				if !v.IsValid() {
+					t.Fatalf("expected api key validation to be successful; got %v", v.Errors)
+				}
+				return
+			}
+
+			if err := v.Errors[tc.field]; err != tc.wantErr {
+				t.Errorf("expected %s error to be %s; got %s", tc.field, tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,10 +3,15 @@ package model
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
 )
 
 type Book struct {
@@ -16,37 +21,217 @@ type Book struct {
 	PublishedDate string    `json:"published_date"`
 	PageCount     int       `json:"page_count"`
 	Categories    []string  `json:"categories"`
+	Price         Money     `json:"price"`
+	AverageRating float64   `json:"average_rating"`
+	ReviewCount   int       `json:"review_count"`
 	Version       int32     `json:"version"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// BookSearch holds the criteria used to narrow down the list of books. Price
+// bounds are in the minor unit of Currency, and nil bounds are ignored, as is
+// a nil InStock.
+type BookSearch struct {
+	Title      string
+	Categories []string
+	MinRating  float64
+	Currency   string
+	MinPrice   *int64
+	MaxPrice   *int64
+	InStock    *bool
+}
+
 type BookModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration
 }
 
 type BookStore interface {
-	GetAll(string, []string, Filters) ([]*Book, Metadata, error)
+	Insert(context.Context, *Book) error
+	Get(context.Context, int64) (*Book, error)
+	GetAll(context.Context, BookSearch, Filters) ([]*Book, Metadata, error)
+	Update(context.Context, *Book) error
+	Delete(context.Context, int64) error
+}
+
+var publishedDateRX = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func ValidateBook(v *validator.Validator, book *Book) {
+	validateBookTitle(v, book.Title)
+	validateBookList(v, "authors", "author", book.Authors)
+	validateBookPublishedDate(v, book.PublishedDate)
+	validateBookPageCount(v, book.PageCount)
+	validateBookList(v, "categories", "category", book.Categories)
+	ValidatePrice(v, "price", book.Price)
+}
+
+func validateBookTitle(v *validator.Validator, title string) {
+	if strings.TrimSpace(title) == "" {
+		v.AddError("title", "must be provided")
+		return
+	}
+	if len(title) > 500 {
+		v.AddError("title", "cannot be more than 500 bytes long")
+		return
+	}
+}
+
+func validateBookList(v *validator.Validator, key string, name string, values []string) {
+	if len(values) < 1 {
+		v.AddError(key, fmt.Sprintf("must contain at least 1 %s", name))
+		return
+	}
+	if len(values) > 10 {
+		v.AddError(key, fmt.Sprintf("cannot contain more than 10 %s", key))
+		return
+	}
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			v.AddError(key, fmt.Sprintf("cannot contain an empty %s", name))
+			return
+		}
+	}
+	if !validator.Unique(values) {
+		v.AddError(key, "cannot contain duplicate values")
+		return
+	}
+}
+
+func validateBookPublishedDate(v *validator.Validator, publishedDate string) {
+	if publishedDate == "" {
+		v.AddError("published_date", "must be provided")
+		return
+	}
+	if !validator.Matches(publishedDate, publishedDateRX) {
+		v.AddError("published_date", "must be in yyyy-mm-dd format")
+		return
+	}
+	date, err := time.Parse(time.DateOnly, publishedDate)
+	if err != nil {
+		v.AddError("published_date", "must be a valid date")
+		return
+	}
+	if date.After(time.Now()) {
+		v.AddError("published_date", "cannot be in the future")
+		return
+	}
+}
+
+func validateBookPageCount(v *validator.Validator, pageCount int) {
+	if !validator.ValueInRange(pageCount, 1, 100_000) {
+		v.AddError("page_count", "must be between 1 and 100000")
+		return
+	}
+}
+
+func (m BookModel) Insert(ctx context.Context, book *Book) error {
+	query := `
+		INSERT INTO books (title, authors, published_date, page_count, categories, price,
+            price_currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, version, created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		book.Title,
+		pq.Array(book.Authors),
+		book.PublishedDate,
+		book.PageCount,
+		pq.Array(book.Categories),
+		book.Price.Amount,
+		book.Price.Currency,
+	}
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&book.ID,
+		&book.Version,
+		&book.CreatedAt,
+		&book.UpdatedAt,
+	)
+}
+
+func (m BookModel) Get(ctx context.Context, id int64) (*Book, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, title, authors, TO_CHAR(published_date, 'yyyy-mm-dd'),
+            page_count, categories, price, price_currency, average_rating, review_count,
+            version, created_at, updated_at
+		FROM books
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var book Book
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&book.ID,
+		&book.Title,
+		pq.Array(&book.Authors),
+		&book.PublishedDate,
+		&book.PageCount,
+		pq.Array(&book.Categories),
+		&book.Price.Amount,
+		&book.Price.Currency,
+		&book.AverageRating,
+		&book.ReviewCount,
+		&book.Version,
+		&book.CreatedAt,
+		&book.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &book, nil
 }
 
 func (m BookModel) GetAll(
-	title string,
-	categories []string,
+	ctx context.Context,
+	search BookSearch,
 	filters Filters,
 ) ([]*Book, Metadata, error) {
 	query := fmt.Sprintf(`
 		SELECT count(*) OVER(), id, title, authors, TO_CHAR(published_date, 'yyyy-mm-dd'),
-            page_count, categories, version, created_at, updated_at
+            page_count, categories, price, price_currency, average_rating, review_count,
+            version, created_at, updated_at
 		FROM books
 		WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
         AND (categories @> $2 or $2 = '{}')
-		ORDER BY %s %s, id ASC
-		LIMIT $3 OFFSET $4`, filters.sortColumn(), filters.sortDirection())
+        AND average_rating >= $3
+        AND (price_currency = $4 OR $4 = '')
+        AND (price >= $5 OR $5 IS NULL)
+        AND (price <= $6 OR $6 IS NULL)
+        AND ($7::boolean IS NULL OR EXISTS (
+            SELECT 1 FROM inventory
+            WHERE inventory.book_id = books.id AND inventory.on_hand > inventory.reserved
+        ) = $7::boolean)
+		ORDER BY %[1]s %[2]s, id %[2]s
+		LIMIT $8 OFFSET $9`, bookSortColumn(filters), filters.sortDirection())
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
 	defer cancel()
 
-	args := []interface{}{title, pq.Array(categories), filters.limit(), filters.offset()}
+	args := []interface{}{
+		search.Title,
+		pq.Array(search.Categories),
+		search.MinRating,
+		search.Currency,
+		search.MinPrice,
+		search.MaxPrice,
+		search.InStock,
+		filters.limit(),
+		filters.offset(),
+	}
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -69,6 +254,10 @@ func (m BookModel) GetAll(
 			&book.PublishedDate,
 			&book.PageCount,
 			pq.Array(&book.Categories),
+			&book.Price.Amount,
+			&book.Price.Currency,
+			&book.AverageRating,
+			&book.ReviewCount,
 			&book.Version,
 			&book.CreatedAt,
 			&book.UpdatedAt,
@@ -88,3 +277,77 @@ func (m BookModel) GetAll(
 
 	return books, metadata, nil
 }
+
+// bookSortColumn maps the public sort values of the book list to the columns
+// of the books table. Ties are broken by id in the same direction, so that
+// descending sorts can scan the (column, id) indexes backwards.
+func bookSortColumn(filters Filters) string {
+	column := filters.sortColumn()
+	if column == "rating" {
+		return "average_rating"
+	}
+	return column
+}
+
+func (m BookModel) Update(ctx context.Context, book *Book) error {
+	query := `
+		UPDATE books
+		SET title = $3, authors = $4, published_date = $5, page_count = $6,
+            categories = $7, price = $8, price_currency = $9, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		book.ID,
+		book.Version,
+		book.Title,
+		pq.Array(book.Authors),
+		book.PublishedDate,
+		book.PageCount,
+		pq.Array(book.Categories),
+		book.Price.Amount,
+		book.Price.Currency,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&book.Version, &book.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m BookModel) Delete(ctx context.Context, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM books
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func newTestBook() *Book {
+	return &Book{
+		Title:         "Test Book",
+		Authors:       []string{"Test Author"},
+		PublishedDate: "2020-01-01",
+		PageCount:     100,
+		Categories:    []string{"Drama"},
+		Price:         Money{Amount: 1299, Currency: "USD"},
+	}
+}
+
+func TestValidateBook(t *testing.T) {
+	t.Parallel()
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+
+	testCases := []struct {
+		name    string
+		modify  func(b *Book)
+		key     string
+		wantErr string
+	}{
+		{
+			name:    "empty_title",
+			modify:  func(b *Book) { b.Title = " " },
+			key:     "title",
+			wantErr: "must be provided",
+		},
+		{
+			name:    "long_title",
+			modify:  func(b *Book) { b.Title = strings.Repeat("a", 501) },
+			key:     "title",
+			wantErr: "cannot be more than 500 bytes long",
+		},
+		{
+			name:    "no_authors",
+			modify:  func(b *Book) { b.Authors = nil },
+			key:     "authors",
+			wantErr: "must contain at least 1 author",
+		},
+		{
+			name:    "empty_author",
+			modify:  func(b *Book) { b.Authors = []string{""} },
+			key:     "authors",
+			wantErr: "cannot contain an empty author",
+		},
+		{
+			name:    "duplicate_categories",
+			modify:  func(b *Book) { b.Categories = []string{"Drama", "Drama"} },
+			key:     "categories",
+			wantErr: "cannot contain duplicate values",
+		},
+		{
+			name:    "invalid_date_format",
+			modify:  func(b *Book) { b.PublishedDate = "01/01/2020" },
+			key:     "published_date",
+			wantErr: "must be in yyyy-mm-dd format",
+		},
+		{
+			name:    "invalid_date",
+			modify:  func(b *Book) { b.PublishedDate = "2020-02-31" },
+			key:     "published_date",
+			wantErr: "must be a valid date",
+		},
+		{
+			name:    "future_date",
+			modify:  func(b *Book) { b.PublishedDate = tomorrow },
+			key:     "published_date",
+			wantErr: "cannot be in the future",
+		},
+		{
+			name:    "zero_page_count",
+			modify:  func(b *Book) { b.PageCount = 0 },
+			key:     "page_count",
+			wantErr: "must be between 1 and 100000",
+		},
+		{
+			name:    "negative_price",
+			modify:  func(b *Book) { b.Price.Amount = -1 },
+			key:     "price",
+			wantErr: "cannot be negative",
+		},
+		{
+			name:    "unsupported_currency",
+			modify:  func(b *Book) { b.Price.Currency = "XYZ" },
+			key:     "price",
+			wantErr: "must use a supported currency",
+		},
+		{
+			name:   "success",
+			modify: func(b *Book) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			book := newTestBook()
+			tc.modify(book)
+
+			v := validator.NewValidator()
+			ValidateBook(v, book)
+
+			if tc.wantErr == "" {
+				if !v.IsValid() {
+					t.Fatalf("expected book validation to be successful; got %v", v.Errors)
+				}
+				return
+			}
+
+			err, ok := v.Errors[tc.key]
+			if !ok {
+				t.Fatalf("expected %s field to exist in validation error", tc.key)
+			}
+
+			if err != tc.wantErr {
+				t.Errorf("expected %s error to be %s; got %s", tc.key, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBookSortColumn(t *testing.T) {
+	t.Parallel()
+
+	safeList := []string{"id", "rating", "-id", "-rating"}
+
+	testCases := []struct {
+		sort       string
+		wantColumn string
+	}{
+		{sort: "id", wantColumn: "id"},
+		{sort: "-id", wantColumn: "id"},
+		{sort: "rating", wantColumn: "average_rating"},
+		{sort: "-rating", wantColumn: "average_rating"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sort, func(t *testing.T) {
+			t.Parallel()
+
+			column := bookSortColumn(Filters{Sort: tc.sort, SortSafeList: safeList})
+			if column != tc.wantColumn {
+				t.Errorf("expected sort column to be %s; got %s", tc.wantColumn, column)
+			}
+		})
+	}
+}
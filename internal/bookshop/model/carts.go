@@ -0,0 +1,280 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const maxCartItemQuantity = 100
+
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+// CartItem is a book in a cart. UnitPrice is the price of the book when it was
+// first added to the cart.
+type CartItem struct {
+	BookID    int64     `json:"book_id"`
+	Title     string    `json:"title"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice Money     `json:"unit_price"`
+	LineTotal Money     `json:"line_total"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Cart struct {
+	Items     []*CartItem `json:"items"`
+	ItemCount int         `json:"item_count"`
+	Subtotal  Money       `json:"subtotal"`
+}
+
+// NewCart returns a cart holding items, with its line totals, item count and
+// subtotal calculated. The items must share a currency, which AddItem ensures.
+func NewCart(items []*CartItem) *Cart {
+	cart := &Cart{Items: items, Subtotal: Money{Currency: DefaultCurrency}}
+	if cart.Items == nil {
+		cart.Items = []*CartItem{}
+	}
+	if len(cart.Items) > 0 {
+		cart.Subtotal.Currency = cart.Items[0].UnitPrice.Currency
+	}
+	for _, item := range cart.Items {
+		item.LineTotal = item.UnitPrice.Multiply(int64(item.Quantity))
+		cart.ItemCount += item.Quantity
+		cart.Subtotal.Amount += item.LineTotal.Amount
+	}
+	return cart
+}
+
+type CartModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type CartStore interface {
+	GetForUser(context.Context, int64) (*Cart, error)
+	AddItem(context.Context, int64, int64, int) error
+	UpdateItem(context.Context, int64, int64, int) error
+	RemoveItem(context.Context, int64, int64) error
+}
+
+func ValidateCartItemQuantity(v *validator.Validator, quantity int) {
+	if !validator.ValueInRange(quantity, 1, maxCartItemQuantity) {
+		v.AddError("quantity", "must be between 1 and 100")
+		return
+	}
+}
+
+func (m CartModel) GetForUser(ctx context.Context, userID int64) (*Cart, error) {
+	query := `
+		SELECT cart_items.book_id, books.title, cart_items.quantity, cart_items.unit_price,
+            cart_items.currency, cart_items.created_at, cart_items.updated_at
+		FROM cart_items
+		INNER JOIN carts ON carts.id = cart_items.cart_id
+		INNER JOIN books ON books.id = cart_items.book_id
+		WHERE carts.user_id = $1
+		ORDER BY cart_items.created_at ASC, cart_items.book_id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []*CartItem
+
+	for rows.Next() {
+		var item CartItem
+
+		err := rows.Scan(
+			&item.BookID,
+			&item.Title,
+			&item.Quantity,
+			&item.UnitPrice.Amount,
+			&item.UnitPrice.Currency,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewCart(items), nil
+}
+
+// AddItem adds quantity copies of a book to the cart of a user, creating the
+// cart when needed. Adding a book that is already in the cart increases its
+// quantity and keeps its original unit price. Books priced in a currency other
+// than the one of the cart are rejected with ErrCurrencyMismatch.
+func (m CartModel) AddItem(ctx context.Context, userID int64, bookID int64, quantity int) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	// Upserting the cart locks its row, so concurrent additions to the same
+	// cart run one after the other.
+	query := `
+		INSERT INTO carts (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = NOW()
+		RETURNING id`
+
+	var cartID int64
+
+	if err := tx.QueryRowContext(ctx, query, userID).Scan(&cartID); err != nil {
+		return err
+	}
+
+	query = `
+		SELECT price, price_currency
+		FROM books
+		WHERE id = $1`
+
+	var price Money
+
+	if err := tx.QueryRowContext(ctx, query, bookID).Scan(&price.Amount, &price.Currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	query = `
+		SELECT EXISTS (SELECT 1 FROM cart_items WHERE cart_id = $1 AND currency <> $2)`
+
+	var mismatch bool
+
+	if err := tx.QueryRowContext(ctx, query, cartID, price.Currency).Scan(&mismatch); err != nil {
+		return err
+	}
+
+	if mismatch {
+		return ErrCurrencyMismatch
+	}
+
+	query = `
+		INSERT INTO cart_items (cart_id, book_id, quantity, unit_price, currency)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (cart_id, book_id) DO UPDATE
+		SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = NOW()`
+
+	args := []interface{}{cartID, bookID, quantity, price.Amount, price.Currency}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		if isCheckViolation(err, "cart_items_quantity_check") {
+			return ErrInvalidQuantity
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m CartModel) UpdateItem(ctx context.Context, userID int64, bookID int64, quantity int) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		cartID, err := lockCart(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE cart_items
+			SET quantity = $3, updated_at = NOW()
+			WHERE cart_id = $1 AND book_id = $2`
+
+		result, err := tx.ExecContext(ctx, query, cartID, bookID, quantity)
+		if err != nil {
+			if isCheckViolation(err, "cart_items_quantity_check") {
+				return ErrInvalidQuantity
+			}
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+func (m CartModel) RemoveItem(ctx context.Context, userID int64, bookID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		cartID, err := lockCart(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			DELETE FROM cart_items
+			WHERE cart_id = $1 AND book_id = $2`
+
+		result, err := tx.ExecContext(ctx, query, cartID, bookID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+// lockCart locks the cart row of the user for the rest of the transaction.
+// Every change to the items of a cart takes this lock first, so checkouts and
+// cart changes of the same user run one after the other.
+func lockCart(ctx context.Context, tx *sql.Tx, userID int64) (int64, error) {
+	query := `
+		SELECT id
+		FROM carts
+		WHERE user_id = $1
+		FOR UPDATE`
+
+	var cartID int64
+
+	if err := tx.QueryRowContext(ctx, query, userID).Scan(&cartID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return cartID, nil
+}
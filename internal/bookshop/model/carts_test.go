@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestNewCart(t *testing.T) {
+	t.Parallel()
+
+	cart := NewCart(nil)
+
+	if cart.Items == nil || cart.ItemCount != 0 || cart.Subtotal.Amount != 0 {
+		t.Errorf("expected empty cart; got %+v", cart)
+	}
+
+	cart = NewCart([]*CartItem{
+		{BookID: 1, Quantity: 2, UnitPrice: Money{Amount: 1000, Currency: "EUR"}},
+		{BookID: 2, Quantity: 1, UnitPrice: Money{Amount: 1550, Currency: "EUR"}},
+	})
+
+	if cart.Items[0].LineTotal.Amount != 2000 {
+		t.Errorf("expected line total to be 2000; got %d", cart.Items[0].LineTotal.Amount)
+	}
+
+	if cart.ItemCount != 3 {
+		t.Errorf("expected item count to be 3; got %d", cart.ItemCount)
+	}
+
+	if cart.Subtotal.Amount != 3550 || cart.Subtotal.Currency != "EUR" {
+		t.Errorf("expected subtotal to be 3550 EUR; got %d %s", cart.Subtotal.Amount, cart.Subtotal.Currency)
+	}
+}
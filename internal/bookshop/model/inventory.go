@@ -0,0 +1,218 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	MovementReasonReceived   = "received"
+	MovementReasonDamaged    = "damaged"
+	MovementReasonCorrection = "correction"
+	MovementReasonSale       = "sale"
+)
+
+// Inventory is the stock of a book. Reserved copies are on hand but promised to
+// orders, so only the available copies can be sold.
+type Inventory struct {
+	BookID    int64     `json:"book_id"`
+	OnHand    int       `json:"on_hand"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InventoryMovement is an entry of the stock ledger of a book. Quantity is the
+// change to the on-hand copies, negative when copies leave the shop.
+type InventoryMovement struct {
+	ID        int64     `json:"id"`
+	BookID    int64     `json:"book_id"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason"`
+	Note      string    `json:"note,omitempty"`
+	OrderID   *int64    `json:"order_id,omitempty"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InventoryModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type InventoryStore interface {
+	Get(context.Context, int64) (*Inventory, error)
+	Adjust(context.Context, *InventoryMovement) (*Inventory, error)
+	GetMovements(context.Context, int64, Filters) ([]*InventoryMovement, Metadata, error)
+}
+
+// ValidateInventoryAdjustment checks a staff adjustment. Sales are recorded by
+// checkout and can't be entered by hand.
+func ValidateInventoryAdjustment(v *validator.Validator, movement *InventoryMovement) {
+	reasons := []string{MovementReasonReceived, MovementReasonDamaged, MovementReasonCorrection}
+
+	if !validator.ValueInList(movement.Reason, reasons...) {
+		v.AddError("reason", "must be one of received, damaged or correction")
+	}
+
+	switch {
+	case movement.Quantity == 0:
+		v.AddError("quantity", "cannot be zero")
+	case !validator.ValueInRange(movement.Quantity, -100_000, 100_000):
+		v.AddError("quantity", "must be between -100000 and 100000")
+	case movement.Reason == MovementReasonReceived && movement.Quantity < 0:
+		v.AddError("quantity", "must be positive for received stock")
+	case movement.Reason == MovementReasonDamaged && movement.Quantity > 0:
+		v.AddError("quantity", "must be negative for damaged stock")
+	}
+
+	if len(movement.Note) > 500 {
+		v.AddError("note", "cannot be more than 500 bytes long")
+	}
+}
+
+// Get returns the stock of a book, which is empty when the book has never been
+// stocked.
+func (m InventoryModel) Get(ctx context.Context, bookID int64) (*Inventory, error) {
+	query := `
+		SELECT book_id, on_hand, reserved, updated_at
+		FROM inventory
+		WHERE book_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	inventory := Inventory{BookID: bookID}
+
+	err := m.DB.QueryRowContext(ctx, query, bookID).Scan(
+		&inventory.BookID,
+		&inventory.OnHand,
+		&inventory.Reserved,
+		&inventory.UpdatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	inventory.Available = inventory.OnHand - inventory.Reserved
+
+	return &inventory, nil
+}
+
+// Adjust applies a staff adjustment to the stock of a book and records it in
+// the ledger. Adjustments leaving fewer copies on hand than reserved fail with
+// ErrInsufficientStock.
+func (m InventoryModel) Adjust(ctx context.Context, movement *InventoryMovement) (*Inventory, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	inventory := Inventory{BookID: movement.BookID}
+
+	err := withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO inventory (book_id, on_hand)
+			VALUES ($1, $2)
+			ON CONFLICT (book_id) DO UPDATE
+			SET on_hand = inventory.on_hand + EXCLUDED.on_hand, updated_at = NOW()
+			RETURNING on_hand, reserved, updated_at`
+
+		err := tx.QueryRowContext(ctx, query, movement.BookID, movement.Quantity).Scan(
+			&inventory.OnHand,
+			&inventory.Reserved,
+			&inventory.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		return insertMovement(ctx, tx, movement)
+	})
+	if err != nil {
+		if isCheckViolation(err, "inventory_on_hand_check") || isCheckViolation(err, "inventory_reserved_check") {
+			return nil, ErrInsufficientStock
+		}
+		return nil, err
+	}
+
+	inventory.Available = inventory.OnHand - inventory.Reserved
+
+	return &inventory, nil
+}
+
+func insertMovement(ctx context.Context, tx *sql.Tx, movement *InventoryMovement) error {
+	query := `
+		INSERT INTO inventory_movements (book_id, quantity, reason, note, order_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	args := []interface{}{
+		movement.BookID,
+		movement.Quantity,
+		movement.Reason,
+		movement.Note,
+		movement.OrderID,
+		movement.CreatedBy,
+	}
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+func (m InventoryModel) GetMovements(
+	ctx context.Context,
+	bookID int64,
+	filters Filters,
+) ([]*InventoryMovement, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), id, book_id, quantity, reason, note, order_id, created_by, created_at
+		FROM inventory_movements
+		WHERE book_id = $1
+		ORDER BY %s %s, id ASC
+		LIMIT $2 OFFSET $3`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, bookID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	movements := []*InventoryMovement{}
+	totalRecords := 0
+
+	for rows.Next() {
+		var movement InventoryMovement
+
+		err := rows.Scan(
+			&totalRecords,
+			&movement.ID,
+			&movement.BookID,
+			&movement.Quantity,
+			&movement.Reason,
+			&movement.Note,
+			&movement.OrderID,
+			&movement.CreatedBy,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		movements = append(movements, &movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return movements, metadata, nil
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func TestValidateInventoryAdjustment(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		movement InventoryMovement
+		key      string
+		wantErr  string
+	}{
+		{
+			name:     "sale",
+			movement: InventoryMovement{Quantity: -1, Reason: MovementReasonSale},
+			key:      "reason",
+			wantErr:  "must be one of received, damaged or correction",
+		},
+		{
+			name:     "zero_quantity",
+			movement: InventoryMovement{Quantity: 0, Reason: MovementReasonCorrection},
+			key:      "quantity",
+			wantErr:  "cannot be zero",
+		},
+		{
+			name:     "negative_received",
+			movement: InventoryMovement{Quantity: -1, Reason: MovementReasonReceived},
+			key:      "quantity",
+			wantErr:  "must be positive for received stock",
+		},
+		{
+			name:     "positive_damaged",
+			movement: InventoryMovement{Quantity: 1, Reason: MovementReasonDamaged},
+			key:      "quantity",
+			wantErr:  "must be negative for damaged stock",
+		},
+		{
+			name:     "success",
+			movement: InventoryMovement{Quantity: -3, Reason: MovementReasonCorrection},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validator.NewValidator()
+			ValidateInventoryAdjustment(v, &tc.movement)
+
+			if tc.wantErr == "" {
+				if !v.IsValid() {
+					t.Fatalf("expected adjustment validation to be successful; got %v", v.Errors)
+				}
+				return
+			}
+
+			if err := v.Errors[tc.key]; err != tc.wantErr {
+				t.Errorf("expected %s error to be %s; got %s", tc.key, tc.wantErr, err)
+			}
+		})
+	}
+}
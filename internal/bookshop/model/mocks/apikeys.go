@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+const PartnerAPIKey = "bsk_partnerkeypartnerkeypartnerkeypa"
+
+type APIKeyModel struct {
+	DB *sql.DB
+}
+
+func newAPIKey() *model.APIKey {
+	return &model.APIKey{
+		ID:     1,
+		Name:   "Test Partner",
+		Prefix: PartnerAPIKey[:12],
+		Scopes: model.Permissions{model.PermissionBooksRead},
+		RPS:    1,
+		Burst:  1,
+	}
+}
+
+func (m APIKeyModel) Insert(ctx context.Context, key *model.APIKey) error {
+	key.ID = 2
+	return nil
+}
+
+func (m APIKeyModel) GetAll(ctx context.Context) ([]*model.APIKey, error) {
+	return []*model.APIKey{newAPIKey()}, nil
+}
+
+func (m APIKeyModel) GetForPlaintext(ctx context.Context, plaintext string) (*model.APIKey, error) {
+	if plaintext != PartnerAPIKey {
+		return nil, model.ErrRecordNotFound
+	}
+	return newAPIKey(), nil
+}
+
+func (m APIKeyModel) Revoke(ctx context.Context, id int64) error {
+	if id != 1 {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
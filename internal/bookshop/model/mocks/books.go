@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
@@ -10,16 +11,57 @@ type BookModel struct {
 	DB *sql.DB
 }
 
+func (m BookModel) Insert(ctx context.Context, book *model.Book) error {
+	book.ID = 2
+	book.Version = 1
+	return nil
+}
+
+func (m BookModel) Get(ctx context.Context, id int64) (*model.Book, error) {
+	if id != 1 {
+		return nil, model.ErrRecordNotFound
+	}
+	book := &model.Book{
+		ID:            1,
+		Title:         "Test Book 1",
+		Authors:       []string{"Test Author 1"},
+		PublishedDate: "2020-01-01",
+		PageCount:     100,
+		Categories:    []string{"Drama"},
+		Price:         model.Money{Amount: 1000, Currency: model.DefaultCurrency},
+		AverageRating: 4,
+		ReviewCount:   1,
+		Version:       1,
+	}
+	return book, nil
+}
+
 func (m BookModel) GetAll(
-	title string,
-	categories []string,
+	ctx context.Context,
+	search model.BookSearch,
 	filters model.Filters,
 ) ([]*model.Book, model.Metadata, error) {
 	books := []*model.Book{
 		{
 			ID:    1,
 			Title: "Test Book 1",
+			Price: model.Money{Amount: 1000, Currency: model.DefaultCurrency},
 		},
 	}
 	return books, model.Metadata{}, nil
 }
+
+func (m BookModel) Update(ctx context.Context, book *model.Book) error {
+	if book.Version != 1 {
+		return model.ErrEditConflict
+	}
+	book.Version++
+	return nil
+}
+
+func (m BookModel) Delete(ctx context.Context, id int64) error {
+	if id != 1 {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+type CartModel struct {
+	DB *sql.DB
+}
+
+// The cart of the customer user holds two copies of the mocked book, while the
+// cart of every other user is empty.
+func (m CartModel) GetForUser(ctx context.Context, userID int64) (*model.Cart, error) {
+	if userID != customerUserID {
+		return model.NewCart(nil), nil
+	}
+	items := []*model.CartItem{
+		{
+			BookID:    1,
+			Title:     "Test Book 1",
+			Quantity:  2,
+			UnitPrice: model.Money{Amount: 1000, Currency: model.DefaultCurrency},
+		},
+	}
+	return model.NewCart(items), nil
+}
+
+func (m CartModel) AddItem(ctx context.Context, userID int64, bookID int64, quantity int) error {
+	if bookID != 1 {
+		return model.ErrRecordNotFound
+	}
+	if userID == customerUserID && quantity+2 > 100 {
+		return model.ErrInvalidQuantity
+	}
+	return nil
+}
+
+func (m CartModel) UpdateItem(ctx context.Context, userID int64, bookID int64, quantity int) error {
+	if userID != customerUserID || bookID != 1 {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (m CartModel) RemoveItem(ctx context.Context, userID int64, bookID int64) error {
+	if userID != customerUserID || bookID != 1 {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+// mockedOnHand is the number of copies of the mocked book in stock, none of
+// them reserved.
+const mockedOnHand = 5
+
+type InventoryModel struct {
+	DB *sql.DB
+}
+
+func (m InventoryModel) Get(ctx context.Context, bookID int64) (*model.Inventory, error) {
+	inventory := &model.Inventory{BookID: bookID}
+	if bookID == 1 {
+		inventory.OnHand = mockedOnHand
+		inventory.Available = mockedOnHand
+	}
+	return inventory, nil
+}
+
+func (m InventoryModel) Adjust(ctx context.Context, movement *model.InventoryMovement) (*model.Inventory, error) {
+	inventory, err := m.Get(ctx, movement.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if inventory.OnHand+movement.Quantity < 0 {
+		return nil, model.ErrInsufficientStock
+	}
+	inventory.OnHand += movement.Quantity
+	inventory.Available += movement.Quantity
+	movement.ID = 2
+	return inventory, nil
+}
+
+func (m InventoryModel) GetMovements(
+	ctx context.Context,
+	bookID int64,
+	filters model.Filters,
+) ([]*model.InventoryMovement, model.Metadata, error) {
+	if bookID != 1 {
+		return []*model.InventoryMovement{}, model.Metadata{}, nil
+	}
+	movements := []*model.InventoryMovement{
+		{
+			ID:       1,
+			BookID:   1,
+			Quantity: mockedOnHand,
+			Reason:   model.MovementReasonReceived,
+		},
+	}
+	return movements, model.Metadata{}, nil
+}
@@ -7,11 +7,29 @@ import (
 )
 
 type Models struct {
-	Books *BookModel
+	APIKeys     *APIKeyModel
+	Books       *BookModel
+	Carts       *CartModel
+	Inventory   *InventoryModel
+	Orders      *OrderModel
+	Permissions *PermissionModel
+	Reviews     *ReviewModel
+	TOTP        *TOTPModel
+	Tokens      *TokenModel
+	Users       *UserModel
 }
 
 func NewModels(db *sql.DB) model.Models {
 	return model.Models{
-		Books: &BookModel{DB: db},
+		APIKeys:     &APIKeyModel{DB: db},
+		Books:       &BookModel{DB: db},
+		Carts:       &CartModel{DB: db},
+		Inventory:   &InventoryModel{DB: db},
+		Orders:      &OrderModel{DB: db},
+		Permissions: &PermissionModel{DB: db},
+		Reviews:     &ReviewModel{DB: db},
+		TOTP:        &TOTPModel{DB: db},
+		Tokens:      &TokenModel{DB: db},
+		Users:       &UserModel{DB: db},
 	}
 }
@@ -0,0 +1,113 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+)
+
+type OrderModel struct {
+	DB *sql.DB
+}
+
+// newOrder returns the mocked orders. Order 1 is pending, placed by the
+// customer user for the books in their mocked cart, and order 2 was paid by the
+// staff user with the first payment of the fake provider.
+func newOrder(id int64) *model.Order {
+	price := model.Money{Amount: 1000, Currency: model.DefaultCurrency}
+	order := &model.Order{
+		ID:     id,
+		UserID: customerUserID,
+		Status: model.OrderStatusPending,
+		Items: []*model.OrderItem{
+			{
+				BookID:    1,
+				Title:     "Test Book 1",
+				Quantity:  2,
+				UnitPrice: price,
+				LineTotal: price.Multiply(2),
+			},
+		},
+		Total:         price.Multiply(2),
+		Version:       1,
+		ReservedUntil: time.Now().Add(model.OrderReservationTTL),
+	}
+
+	if id == 2 {
+		order.UserID = staffUserID
+		order.Status = model.OrderStatusPaid
+		order.PaymentProvider = payments.FakeProviderName
+		order.PaymentID = "fake_pay_1"
+	}
+
+	return order
+}
+
+// CreateFromCart mirrors the mocked carts, where only the customer user has
+// books in their cart.
+func (m OrderModel) CreateFromCart(ctx context.Context, userID int64) (*model.Order, error) {
+	if userID != customerUserID {
+		return nil, model.ErrEmptyCart
+	}
+	return newOrder(1), nil
+}
+
+func (m OrderModel) Get(ctx context.Context, id int64) (*model.Order, error) {
+	if id != 1 && id != 2 {
+		return nil, model.ErrRecordNotFound
+	}
+	return newOrder(id), nil
+}
+
+// GetByPayment knows the payment of the paid order 2 and a second payment
+// of the fake provider, held by order 1 after it failed.
+func (m OrderModel) GetByPayment(ctx context.Context, provider string, paymentID string) (*model.Order, error) {
+	if provider != payments.FakeProviderName {
+		return nil, model.ErrRecordNotFound
+	}
+
+	switch paymentID {
+	case "fake_pay_1":
+		return newOrder(2), nil
+	case "fake_pay_2":
+		order := newOrder(1)
+		order.Status = model.OrderStatusFailed
+		order.PaymentProvider = payments.FakeProviderName
+		order.PaymentID = paymentID
+		return order, nil
+	default:
+		return nil, model.ErrRecordNotFound
+	}
+}
+
+func (m OrderModel) SetPayment(ctx context.Context, order *model.Order) error {
+	order.Version++
+	return nil
+}
+
+func (m OrderModel) Transition(ctx context.Context, order *model.Order, status string) error {
+	if !model.CanTransitionOrder(order.Status, status) {
+		return model.ErrInvalidStatusTransition
+	}
+	order.Status = status
+	order.Version++
+	return nil
+}
+
+func (m OrderModel) Cancel(ctx context.Context, order *model.Order) error {
+	if order.Status != model.OrderStatusPending || order.PaymentID != "" {
+		return model.ErrInvalidStatusTransition
+	}
+	order.Status = model.OrderStatusFailed
+	order.Version++
+	return nil
+}
+
+// ExpireReservations has nothing to expire, since the mocked pending order is
+// always within its reservation.
+func (m OrderModel) ExpireReservations(ctx context.Context) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+type PermissionModel struct {
+	DB *sql.DB
+}
+
+func (m PermissionModel) GetAllForUser(ctx context.Context, userID int64) (model.Permissions, error) {
+	if userID == staffUserID {
+		return model.Permissions{
+			model.PermissionAPIKeysWrite,
+			model.PermissionBooksRead,
+			model.PermissionBooksWrite,
+			model.PermissionInventoryWrite,
+			model.PermissionOrdersWrite,
+			model.PermissionReviewsModerate,
+		}, nil
+	}
+	return model.Permissions{model.PermissionBooksRead}, nil
+}
+
+func (m PermissionModel) AddForUser(ctx context.Context, userID int64, codes ...string) error {
+	return nil
+}
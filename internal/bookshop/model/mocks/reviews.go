@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+type ReviewModel struct {
+	DB *sql.DB
+}
+
+// newReview returns one of the mocked reviews of the mocked book. Review 1 is
+// written by the customer user and approved, while review 2 is written by the
+// staff user and still pending.
+func newReview(id int64) *model.Review {
+	review := &model.Review{
+		ID:      id,
+		BookID:  1,
+		UserID:  customerUserID,
+		Rating:  4,
+		Body:    fmt.Sprintf("Test Review %d", id),
+		Status:  model.ReviewStatusApproved,
+		Version: 1,
+	}
+	if id == 2 {
+		review.UserID = staffUserID
+		review.Status = model.ReviewStatusPending
+		review.Flagged = true
+	}
+	return review
+}
+
+func (m ReviewModel) Insert(ctx context.Context, review *model.Review) error {
+	if review.BookID == 1 && review.UserID == customerUserID {
+		return model.ErrDuplicateReview
+	}
+	review.ID = 3
+	review.Version = 1
+	return nil
+}
+
+func (m ReviewModel) Get(ctx context.Context, bookID int64, id int64) (*model.Review, error) {
+	if bookID != 1 {
+		return nil, model.ErrRecordNotFound
+	}
+	return m.GetByID(ctx, id)
+}
+
+func (m ReviewModel) GetByID(ctx context.Context, id int64) (*model.Review, error) {
+	if id != 1 && id != 2 {
+		return nil, model.ErrRecordNotFound
+	}
+	return newReview(id), nil
+}
+
+func (m ReviewModel) GetAllForBook(
+	ctx context.Context,
+	bookID int64,
+	filters model.Filters,
+) ([]*model.Review, model.Metadata, error) {
+	if bookID != 1 {
+		return []*model.Review{}, model.Metadata{}, nil
+	}
+	return []*model.Review{newReview(1)}, model.Metadata{}, nil
+}
+
+func (m ReviewModel) GetAllForModeration(
+	ctx context.Context,
+	search model.ReviewSearch,
+	filters model.Filters,
+) ([]*model.Review, model.Metadata, error) {
+	reviews := []*model.Review{}
+	for _, review := range []*model.Review{newReview(1), newReview(2)} {
+		if review.Status != search.Status {
+			continue
+		}
+		if search.Flagged != nil && review.Flagged != *search.Flagged {
+			continue
+		}
+		if search.BookID != 0 && review.BookID != search.BookID {
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, model.Metadata{}, nil
+}
+
+func (m ReviewModel) Update(ctx context.Context, review *model.Review) error {
+	if review.Version != 1 {
+		return model.ErrEditConflict
+	}
+	review.Version++
+	return nil
+}
+
+func (m ReviewModel) Moderate(ctx context.Context, review *model.Review) error {
+	if review.Version != 1 {
+		return model.ErrEditConflict
+	}
+	review.Version++
+	return nil
+}
+
+func (m ReviewModel) Delete(ctx context.Context, bookID int64, id int64) error {
+	if bookID != 1 || (id != 1 && id != 2) {
+		return model.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+const (
+	ActivationToken             = "ACTIVATIONTOKENACTIVATIONT"
+	AuthenticationToken         = "AUTHENTICATIONTOKENAUTHENT"
+	CustomerAuthenticationToken = "CUSTOMERTOKENCUSTOMERTOKEN"
+	PasswordResetToken          = "PASSWORDRESETTOKENPASSWORD"
+	TwoFactorToken              = "TWOFACTORTOKENTWOFACTORTOK"
+)
+
+// tokens maps each token scope to the only plaintext token considered valid
+// for that scope by the mocked models.
+var tokens = map[string]string{
+	model.ScopeActivation:     ActivationToken,
+	model.ScopeAuthentication: AuthenticationToken,
+	model.ScopePasswordReset:  PasswordResetToken,
+	model.ScopeTwoFactor:      TwoFactorToken,
+}
+
+type TokenModel struct {
+	DB *sql.DB
+}
+
+func (m TokenModel) New(ctx context.Context, userID int64, ttl time.Duration, scope string) (*model.Token, error) {
+	return model.GenerateToken(userID, ttl, scope)
+}
+
+func (m TokenModel) Insert(ctx context.Context, token *model.Token) error {
+	return nil
+}
+
+func (m TokenModel) DeleteAllForUser(ctx context.Context, scope string, userID int64) error {
+	return nil
+}
+
+func (m TokenModel) RecordFailedAttempt(ctx context.Context, scope string, plaintext string, maxAttempts int) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+const (
+	TOTPSecret   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	RecoveryCode = "abcde-fghij"
+)
+
+type TOTPModel struct {
+	DB *sql.DB
+}
+
+// Get returns an enabled TOTP for the staff user and a pending enrollment for
+// the customer user, so that both the login and the enrollment flows can be
+// exercised.
+func (m TOTPModel) Get(ctx context.Context, userID int64) (*model.TOTP, error) {
+	if userID != staffUserID && userID != customerUserID {
+		return nil, model.ErrRecordNotFound
+	}
+	t := &model.TOTP{
+		UserID:  userID,
+		Secret:  TOTPSecret,
+		Enabled: userID == staffUserID,
+	}
+	return t, nil
+}
+
+func (m TOTPModel) Upsert(ctx context.Context, t *model.TOTP) error {
+	return nil
+}
+
+func (m TOTPModel) Enable(ctx context.Context, userID int64, recoveryCodes []string) error {
+	return nil
+}
+
+// UseCounter accepts every code, since each test signs in with a fresh one.
+func (m TOTPModel) UseCounter(ctx context.Context, userID int64, counter int64) (bool, error) {
+	return true, nil
+}
+
+func (m TOTPModel) UseRecoveryCode(ctx context.Context, userID int64, plaintext string) (bool, error) {
+	return userID == staffUserID && plaintext == RecoveryCode, nil
+}
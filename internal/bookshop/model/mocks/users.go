@@ -0,0 +1,93 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+const (
+	UserEmail     = "test@example.com"
+	CustomerEmail = "customer@example.com"
+	UserPassword  = "pa55word"
+)
+
+// staffUserID identifies the mocked user that holds every permission, while
+// customerUserID identifies a mocked user that can only read the catalog.
+const (
+	staffUserID    = 1
+	customerUserID = 3
+)
+
+type UserModel struct {
+	DB *sql.DB
+}
+
+func newUser(id int64) *model.User {
+	email := UserEmail
+	if id == customerUserID {
+		email = CustomerEmail
+	}
+	return &model.User{
+		ID:        id,
+		Name:      "Test User",
+		Email:     email,
+		Activated: true,
+		Version:   1,
+	}
+}
+
+func (m UserModel) Register(ctx context.Context, user *model.User, activation *model.Token, permissions ...string) error {
+	if user.Email == UserEmail || user.Email == CustomerEmail {
+		return model.ErrDuplicateEmail
+	}
+	user.ID = 2
+	user.Version = 1
+	activation.UserID = user.ID
+	return nil
+}
+
+func (m UserModel) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	var user *model.User
+	switch email {
+	case UserEmail:
+		user = newUser(staffUserID)
+	case CustomerEmail:
+		user = newUser(customerUserID)
+	default:
+		return nil, model.ErrRecordNotFound
+	}
+	if err := user.Password.Set(UserPassword); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (m UserModel) GetForToken(ctx context.Context, scope string, plaintext string) (*model.User, error) {
+	if scope == model.ScopeAuthentication && plaintext == CustomerAuthenticationToken {
+		return newUser(customerUserID), nil
+	}
+	if plaintext != tokens[scope] {
+		return nil, model.ErrRecordNotFound
+	}
+	user := newUser(staffUserID)
+	if scope == model.ScopeActivation {
+		user.Activated = false
+	}
+	// The staff user has two-factor authentication enabled and signs in with it.
+	user.TwoFactor = scope == model.ScopeAuthentication
+	return user, nil
+}
+
+func (m UserModel) Update(ctx context.Context, user *model.User) error {
+	if user.Version != 1 {
+		return model.ErrEditConflict
+	}
+	user.Version++
+	return nil
+}
+
+func (m UserModel) ResetPassword(ctx context.Context, user *model.User) error {
+	return m.Update(ctx, user)
+}
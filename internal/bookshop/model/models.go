@@ -1,13 +1,76 @@
 package model
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrEditConflict   = errors.New("edit conflict")
+	ErrRecordNotFound = errors.New("record not found")
+)
 
 type Models struct {
-	Books BookStore
+	APIKeys     APIKeyStore
+	Books       BookStore
+	Carts       CartStore
+	Inventory   InventoryStore
+	Orders      OrderStore
+	Permissions PermissionStore
+	Reviews     ReviewStore
+	TOTP        TOTPStore
+	Tokens      TokenStore
+	Users       UserStore
 }
 
-func NewModels(db *sql.DB) Models {
+func NewModels(db *sql.DB, timeout time.Duration) Models {
 	return Models{
-		Books: &BookModel{DB: db},
+		APIKeys:     &APIKeyModel{DB: db, Timeout: timeout},
+		Books:       &BookModel{DB: db, Timeout: timeout},
+		Carts:       &CartModel{DB: db, Timeout: timeout},
+		Inventory:   &InventoryModel{DB: db, Timeout: timeout},
+		Orders:      &OrderModel{DB: db, Timeout: timeout},
+		Permissions: &PermissionModel{DB: db, Timeout: timeout},
+		Reviews:     &ReviewModel{DB: db, Timeout: timeout},
+		TOTP:        &TOTPModel{DB: db, Timeout: timeout},
+		Tokens:      &TokenModel{DB: db, Timeout: timeout},
+		Users:       &UserModel{DB: db, Timeout: timeout},
+	}
+}
+
+// withTransaction runs fn inside a database transaction, which is committed
+// when fn succeeds and rolled back otherwise.
+func withTransaction(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" && pqErr.Constraint == constraint
+	}
+	return false
+}
+
+func isCheckViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23514" && pqErr.Constraint == constraint
 	}
+	return false
 }
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	DefaultCurrency = "USD"
+	maxPriceAmount  = 10_000_000
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// currencyExponents maps the supported ISO 4217 currency codes to the number
+// of digits of their minor unit.
+var currencyExponents = map[string]int{
+	"AUD": 2,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"USD": 2,
+}
+
+var decimalRX = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// Money is an amount in the minor unit of its currency, such as cents for USD.
+// It's serialized as a decimal string so clients never deal with floats.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// ParseMoney parses a non-negative decimal amount, such as "12.99", in the
+// given currency.
+func ParseMoney(amount string, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	if !decimalRX.MatchString(amount) {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("amount %q has too many decimal places for %s", amount, currency)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %q is too large", amount)
+	}
+
+	return Money{Amount: value, Currency: currency}, nil
+}
+
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+func (m Money) exponent() int {
+	if exponent, ok := currencyExponents[m.Currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// String returns the amount as a decimal string, without the currency.
+func (m Money) String() string {
+	exponent := m.exponent()
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if exponent == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+
+	divisor := int64(math.Pow10(exponent))
+
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/divisor, exponent, amount%divisor)
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Multiply(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.String(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var input moneyJSON
+
+	if err := json.Unmarshal(data, &input); err != nil {
+		return errors.New("money must be an object with amount and currency strings")
+	}
+
+	money, err := ParseMoney(input.Amount, input.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid money value: %w", err)
+	}
+
+	*m = money
+	return nil
+}
+
+func ValidatePrice(v *validator.Validator, key string, price Money) {
+	if !IsSupportedCurrency(price.Currency) {
+		v.AddError(key, "must use a supported currency")
+		return
+	}
+	if price.Amount < 0 {
+		v.AddError(key, "cannot be negative")
+		return
+	}
+	if price.Amount > maxPriceAmount {
+		maxPrice := Money{Amount: maxPriceAmount, Currency: price.Currency}
+		v.AddError(key, fmt.Sprintf("cannot be more than %s", maxPrice))
+		return
+	}
+}
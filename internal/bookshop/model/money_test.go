@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		amount     string
+		currency   string
+		wantAmount int64
+		wantErr    bool
+	}{
+		{name: "whole", amount: "12", currency: "USD", wantAmount: 1200},
+		{name: "fraction", amount: "12.99", currency: "USD", wantAmount: 1299},
+		{name: "short_fraction", amount: "12.5", currency: "eur", wantAmount: 1250},
+		{name: "no_minor_unit", amount: "1500", currency: "JPY", wantAmount: 1500},
+		{name: "three_digits", amount: "1.005", currency: "KWD", wantAmount: 1005},
+		{name: "too_many_digits", amount: "12.999", currency: "USD", wantErr: true},
+		{name: "negative", amount: "-1.00", currency: "USD", wantErr: true},
+		{name: "not_a_number", amount: "abc", currency: "USD", wantErr: true},
+		{name: "unsupported_currency", amount: "1.00", currency: "XYZ", wantErr: true},
+		{name: "overflow", amount: "99999999999999999999", currency: "USD", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			money, err := ParseMoney(tc.amount, tc.currency)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for amount %s; got %+v", tc.amount, money)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if money.Amount != tc.wantAmount {
+				t.Errorf("expected amount to be %d; got %d", tc.wantAmount, money.Amount)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		money Money
+		want  string
+	}{
+		{money: Money{Amount: 1299, Currency: "USD"}, want: "12.99"},
+		{money: Money{Amount: 5, Currency: "USD"}, want: "0.05"},
+		{money: Money{Amount: 1500, Currency: "JPY"}, want: "1500"},
+		{money: Money{Amount: 1005, Currency: "KWD"}, want: "1.005"},
+		{money: Money{Amount: -250, Currency: "EUR"}, want: "-2.50"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.money.String(); got != tc.want {
+				t.Errorf("expected money to be %s; got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(Money{Amount: 1299, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"amount":"12.99","currency":"USD"}`
+	if string(data) != want {
+		t.Errorf("expected json to be %s; got %s", want, data)
+	}
+
+	var money Money
+
+	if err := json.Unmarshal(data, &money); err != nil {
+		t.Fatal(err)
+	}
+
+	if money.Amount != 1299 || money.Currency != "USD" {
+		t.Errorf("expected money to be 1299 USD; got %d %s", money.Amount, money.Currency)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount": 12.99, "currency": "USD"}`), &money); err == nil {
+		t.Error("expected error for a numeric amount")
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	t.Parallel()
+
+	sum, err := Money{Amount: 100, Currency: "USD"}.Add(Money{Amount: 250, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum.Amount != 350 {
+		t.Errorf("expected sum to be 350; got %d", sum.Amount)
+	}
+
+	if _, err := (Money{Currency: "USD"}).Add(Money{Currency: "EUR"}); err != ErrCurrencyMismatch {
+		t.Errorf("expected error to be %v; got %v", ErrCurrencyMismatch, err)
+	}
+}
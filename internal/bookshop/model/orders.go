@@ -0,0 +1,642 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	OrderStatusPending  = "pending"
+	OrderStatusPaid     = "paid"
+	OrderStatusFailed   = "failed"
+	OrderStatusRefunded = "refunded"
+)
+
+// OrderReservationTTL is how long a pending order holds the stock of its books.
+// Pending orders not paid by then are failed by ExpireReservations.
+const OrderReservationTTL = time.Minute * 30
+
+var (
+	ErrEmptyCart               = errors.New("empty cart")
+	ErrInsufficientStock       = errors.New("insufficient stock")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
+
+// orderTransitions lists the statuses an order can move to from each status.
+// Failed and refunded orders are final.
+var orderTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusPaid, OrderStatusFailed},
+	OrderStatusPaid:    {OrderStatusRefunded},
+}
+
+// CanTransitionOrder reports whether an order can move from one status to
+// another.
+func CanTransitionOrder(from, to string) bool {
+	return slices.Contains(orderTransitions[from], to)
+}
+
+type OrderItem struct {
+	BookID    int64  `json:"book_id"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice Money  `json:"unit_price"`
+	LineTotal Money  `json:"line_total"`
+}
+
+type Order struct {
+	ID        int64        `json:"id"`
+	UserID    int64        `json:"user_id"`
+	Status    string       `json:"status"`
+	Items     []*OrderItem `json:"items"`
+	Total     Money        `json:"total"`
+	Version   int32        `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+
+	ReservedUntil time.Time `json:"reserved_until"`
+
+	PaymentProvider string `json:"payment_provider,omitempty"`
+	PaymentID       string `json:"payment_id,omitempty"`
+}
+
+type OrderModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type OrderStore interface {
+	CreateFromCart(context.Context, int64) (*Order, error)
+	Get(context.Context, int64) (*Order, error)
+	GetByPayment(context.Context, string, string) (*Order, error)
+	SetPayment(context.Context, *Order) error
+	Transition(context.Context, *Order, string) error
+	Cancel(context.Context, *Order) error
+	ExpireReservations(context.Context) (int, error)
+}
+
+// CreateFromCart turns the cart of a user into a pending order. The copies of
+// every book in the cart are reserved until the order is paid or fails, for at
+// most OrderReservationTTL, and the cart is cleared, all within one
+// transaction. Carts asking for more copies than available fail with
+// ErrInsufficientStock and are left untouched.
+func (m OrderModel) CreateFromCart(ctx context.Context, userID int64) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	order := &Order{UserID: userID, Status: OrderStatusPending}
+
+	err := withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		// Cart changes take the same lock (see lockCart), so the items read
+		// below are the ones deleted when the cart is emptied.
+		cartID, err := lockCart(ctx, tx, userID)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrEmptyCart
+			}
+			return err
+		}
+
+		items, err := cartOrderItems(ctx, tx, cartID)
+		if err != nil {
+			return err
+		}
+
+		if len(items) < 1 {
+			return ErrEmptyCart
+		}
+
+		order.Items = items
+		order.Total = Money{Currency: items[0].UnitPrice.Currency}
+
+		for _, item := range items {
+			item.LineTotal = item.UnitPrice.Multiply(int64(item.Quantity))
+			order.Total.Amount += item.LineTotal.Amount
+		}
+
+		query := `
+			INSERT INTO orders (user_id, status, total, currency, reserved_until)
+			VALUES ($1, $2, $3, $4, NOW() + $5 * INTERVAL '1 second')
+			RETURNING id, version, created_at, updated_at, reserved_until`
+
+		args := []interface{}{
+			order.UserID,
+			order.Status,
+			order.Total.Amount,
+			order.Total.Currency,
+			int64(OrderReservationTTL.Seconds()),
+		}
+
+		err = tx.QueryRowContext(ctx, query, args...).Scan(
+			&order.ID,
+			&order.Version,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.ReservedUntil,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := reserveStock(ctx, tx, items); err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			query := `
+				INSERT INTO order_items (order_id, book_id, title, quantity, unit_price, currency)
+				VALUES ($1, $2, $3, $4, $5, $6)`
+
+			args := []interface{}{
+				order.ID,
+				item.BookID,
+				item.Title,
+				item.Quantity,
+				item.UnitPrice.Amount,
+				item.UnitPrice.Currency,
+			}
+
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id = $1`, cartID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// cartOrderItems returns the items of a cart, ordered by book id.
+func cartOrderItems(ctx context.Context, tx *sql.Tx, cartID int64) ([]*OrderItem, error) {
+	query := `
+		SELECT cart_items.book_id, books.title, cart_items.quantity, cart_items.unit_price,
+            cart_items.currency
+		FROM cart_items
+		INNER JOIN books ON books.id = cart_items.book_id
+		WHERE cart_items.cart_id = $1
+		ORDER BY cart_items.book_id ASC`
+
+	rows, err := tx.QueryContext(ctx, query, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []*OrderItem
+
+	for rows.Next() {
+		var item OrderItem
+
+		err := rows.Scan(
+			&item.BookID,
+			&item.Title,
+			&item.Quantity,
+			&item.UnitPrice.Amount,
+			&item.UnitPrice.Currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// reserveStock locks the inventory rows of the ordered books, verifies there
+// are enough available copies of each and reserves them. Rows are locked in
+// book id order, so concurrent checkouts can't deadlock, and a checkout
+// waiting on a lock sees the stock left by the one holding it.
+func reserveStock(ctx context.Context, tx *sql.Tx, items []*OrderItem) error {
+	bookIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		bookIDs = append(bookIDs, item.BookID)
+	}
+
+	query := `
+		SELECT book_id, on_hand - reserved
+		FROM inventory
+		WHERE book_id = ANY($1)
+		ORDER BY book_id ASC
+		FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(bookIDs))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	stock := make(map[int64]int, len(items))
+
+	for rows.Next() {
+		var bookID int64
+		var available int
+
+		if err := rows.Scan(&bookID, &available); err != nil {
+			return err
+		}
+
+		stock[bookID] = available
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if stock[item.BookID] < item.Quantity {
+			return ErrInsufficientStock
+		}
+	}
+
+	for _, item := range items {
+		query := `
+			UPDATE inventory
+			SET reserved = reserved + $2, updated_at = NOW()
+			WHERE book_id = $1`
+
+		if _, err := tx.ExecContext(ctx, query, item.BookID, item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrderModel) Get(ctx context.Context, id int64) (*Order, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, user_id, status, total, currency, version, created_at, updated_at,
+            payment_provider, payment_id, reserved_until
+		FROM orders
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var order Order
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&order.ID,
+		&order.UserID,
+		&order.Status,
+		&order.Total.Amount,
+		&order.Total.Currency,
+		&order.Version,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.PaymentProvider,
+		&order.PaymentID,
+		&order.ReservedUntil,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	query = `
+		SELECT COALESCE(book_id, 0), title, quantity, unit_price, currency
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY id ASC`
+
+	rows, err := m.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	order.Items = []*OrderItem{}
+
+	for rows.Next() {
+		var item OrderItem
+
+		err := rows.Scan(
+			&item.BookID,
+			&item.Title,
+			&item.Quantity,
+			&item.UnitPrice.Amount,
+			&item.UnitPrice.Currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.LineTotal = item.UnitPrice.Multiply(int64(item.Quantity))
+		order.Items = append(order.Items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetByPayment returns the order paid with the given payment of a provider.
+func (m OrderModel) GetByPayment(ctx context.Context, provider string, paymentID string) (*Order, error) {
+	if provider == "" || paymentID == "" {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id
+		FROM orders
+		WHERE payment_provider = $1 AND payment_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var id int64
+
+	err := m.DB.QueryRowContext(ctx, query, provider, paymentID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return m.Get(ctx, id)
+}
+
+// SetPayment records the payment an order is being paid with.
+func (m OrderModel) SetPayment(ctx context.Context, order *Order) error {
+	query := `
+		UPDATE orders
+		SET payment_provider = $3, payment_id = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{order.ID, order.Version, order.PaymentProvider, order.PaymentID}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&order.Version, &order.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Transition moves an order to the given status and settles the copies it
+// reserved: paid orders take them off the shelf and record the sales in the
+// ledger, while failed orders release them. Refunds leave the stock as is,
+// copies sent back are recorded by staff as received. The order is locked
+// while it changes, so concurrent webhooks and requests can't apply the same
+// transition twice; transitions that aren't allowed from the current status
+// fail with ErrInvalidStatusTransition, leaving the current status in order.
+func (m OrderModel) Transition(ctx context.Context, order *Order, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		var current string
+
+		err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, order.ID).Scan(&current)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrRecordNotFound
+			}
+			return err
+		}
+
+		if !CanTransitionOrder(current, status) {
+			order.Status = current
+			return ErrInvalidStatusTransition
+		}
+
+		if status == OrderStatusPaid || status == OrderStatusFailed {
+			if err := settleStock(ctx, tx, order, status == OrderStatusPaid); err != nil {
+				return err
+			}
+		}
+
+		return setOrderStatus(ctx, tx, order, status)
+	})
+}
+
+// ExpireReservations fails the pending orders whose reservation has expired,
+// releasing their stock, and returns how many were failed. Orders holding a
+// payment are left to the provider webhooks.
+func (m OrderModel) ExpireReservations(ctx context.Context) (int, error) {
+	ids, err := m.expiredOrderIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+
+	for _, id := range ids {
+		order, err := m.Get(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				continue
+			}
+			return expired, err
+		}
+
+		ok, err := m.expire(ctx, order)
+		if err != nil {
+			return expired, err
+		}
+
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// expiredOrderIDs returns the ids of up to 100 pending orders past their
+// reservation, so a single sweep stays short.
+func (m OrderModel) expiredOrderIDs(ctx context.Context) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM orders
+		WHERE status = $1 AND reserved_until < NOW() AND payment_id = ''
+		ORDER BY id ASC
+		LIMIT 100`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, OrderStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Cancel fails a pending order that is not being paid and releases its stock.
+// Other orders fail with ErrInvalidStatusTransition.
+func (m OrderModel) Cancel(ctx context.Context, order *Order) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		failed, err := failUnpaidOrder(ctx, tx, order, false)
+		if err != nil {
+			return err
+		}
+
+		if !failed {
+			return ErrInvalidStatusTransition
+		}
+
+		return nil
+	})
+}
+
+func (m OrderModel) expire(ctx context.Context, order *Order) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	expired := false
+
+	err := withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		var err error
+		expired, err = failUnpaidOrder(ctx, tx, order, true)
+		return err
+	})
+
+	return expired, err
+}
+
+// failUnpaidOrder fails an order and releases its stock if, once locked, it's
+// still pending without a payment and, when onlyExpired is set, past its
+// reservation. It reports whether the order was failed.
+func failUnpaidOrder(ctx context.Context, tx *sql.Tx, order *Order, onlyExpired bool) (bool, error) {
+	query := `
+		SELECT status = $2 AND payment_id = '' AND (NOT $3 OR reserved_until < NOW())
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE`
+
+	var unpaid bool
+
+	if err := tx.QueryRowContext(ctx, query, order.ID, OrderStatusPending, onlyExpired).Scan(&unpaid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrRecordNotFound
+		}
+		return false, err
+	}
+
+	if !unpaid {
+		return false, nil
+	}
+
+	if err := settleStock(ctx, tx, order, false); err != nil {
+		return false, err
+	}
+
+	if err := setOrderStatus(ctx, tx, order, OrderStatusFailed); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func setOrderStatus(ctx context.Context, tx *sql.Tx, order *Order, status string) error {
+	query := `
+		UPDATE orders
+		SET status = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING version, updated_at`
+
+	if err := tx.QueryRowContext(ctx, query, order.ID, status).Scan(&order.Version, &order.UpdatedAt); err != nil {
+		return err
+	}
+
+	order.Status = status
+	return nil
+}
+
+// settleStock releases the copies reserved by an order and, when sold, takes
+// them off hand and records the sales in the ledger. Items of books deleted
+// since the order was placed have no stock left to settle.
+func settleStock(ctx context.Context, tx *sql.Tx, order *Order, sold bool) error {
+	for _, item := range order.Items {
+		if item.BookID < 1 {
+			continue
+		}
+
+		query := `
+			UPDATE inventory
+			SET reserved = reserved - $2, updated_at = NOW()
+			WHERE book_id = $1`
+
+		if sold {
+			query = `
+				UPDATE inventory
+				SET on_hand = on_hand - $2, reserved = reserved - $2, updated_at = NOW()
+				WHERE book_id = $1`
+		}
+
+		if _, err := tx.ExecContext(ctx, query, item.BookID, item.Quantity); err != nil {
+			return err
+		}
+
+		if !sold {
+			continue
+		}
+
+		movement := &InventoryMovement{
+			BookID:   item.BookID,
+			Quantity: -item.Quantity,
+			Reason:   MovementReasonSale,
+			OrderID:  &order.ID,
+		}
+
+		if err := insertMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
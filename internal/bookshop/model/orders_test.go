@@ -0,0 +1,32 @@
+package model
+
+import "testing"
+
+func TestCanTransitionOrder(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{from: OrderStatusPending, to: OrderStatusPaid, want: true},
+		{from: OrderStatusPending, to: OrderStatusFailed, want: true},
+		{from: OrderStatusPending, to: OrderStatusRefunded, want: false},
+		{from: OrderStatusPaid, to: OrderStatusRefunded, want: true},
+		{from: OrderStatusPaid, to: OrderStatusPaid, want: false},
+		{from: OrderStatusPaid, to: OrderStatusFailed, want: false},
+		{from: OrderStatusFailed, to: OrderStatusPaid, want: false},
+		{from: OrderStatusRefunded, to: OrderStatusPaid, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+"_"+tc.to, func(t *testing.T) {
+			t.Parallel()
+
+			if got := CanTransitionOrder(tc.from, tc.to); got != tc.want {
+				t.Errorf("expected transition from %s to %s to be %t; got %t", tc.from, tc.to, tc.want, got)
+			}
+		})
+	}
+}
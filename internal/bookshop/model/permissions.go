@@ -0,0 +1,84 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	PermissionAPIKeysWrite    = "api-keys:write"
+	PermissionBooksRead       = "books:read"
+	PermissionBooksWrite      = "books:write"
+	PermissionInventoryWrite  = "inventory:write"
+	PermissionOrdersWrite     = "orders:write"
+	PermissionReviewsModerate = "reviews:moderate"
+)
+
+type Permissions []string
+
+func (p Permissions) Include(code string) bool {
+	return slices.Contains(p, code)
+}
+
+type PermissionModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type PermissionStore interface {
+	GetAllForUser(context.Context, int64) (Permissions, error)
+	AddForUser(context.Context, int64, ...string) error
+}
+
+func (m PermissionModel) GetAllForUser(ctx context.Context, userID int64) (Permissions, error) {
+	query := `
+		SELECT permissions.code
+		FROM permissions
+		INNER JOIN users_permissions ON users_permissions.permission_id = permissions.id
+		WHERE users_permissions.user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var permissions Permissions
+
+	for rows.Next() {
+		var permission string
+
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (m PermissionModel) AddForUser(ctx context.Context, userID int64, codes ...string) error {
+	query := `
+		INSERT INTO users_permissions
+		SELECT $1, permissions.id FROM permissions WHERE permissions.code = ANY($2)
+		ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, pq.Array(codes))
+	return err
+}
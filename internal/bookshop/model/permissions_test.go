@@ -0,0 +1,17 @@
+package model
+
+import "testing"
+
+func TestPermissionsInclude(t *testing.T) {
+	t.Parallel()
+
+	p := Permissions{PermissionBooksRead}
+
+	if !p.Include(PermissionBooksRead) {
+		t.Errorf("expected permissions to include %s", PermissionBooksRead)
+	}
+
+	if p.Include(PermissionBooksWrite) {
+		t.Errorf("expected permissions not to include %s", PermissionBooksWrite)
+	}
+}
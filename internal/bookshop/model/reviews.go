@@ -0,0 +1,445 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+var ErrDuplicateReview = errors.New("duplicate review")
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+type Review struct {
+	ID               int64      `json:"id"`
+	BookID           int64      `json:"book_id"`
+	UserID           int64      `json:"user_id"`
+	Rating           int        `json:"rating"`
+	Body             string     `json:"body"`
+	Status           string     `json:"status"`
+	Flagged          bool       `json:"flagged"`
+	ModerationReason string     `json:"moderation_reason,omitempty"`
+	ModeratedBy      *int64     `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+	Version          int32      `json:"version"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ReviewSearch holds the criteria used to narrow down the moderation queue. A
+// nil Flagged and a zero BookID match every review.
+type ReviewSearch struct {
+	Status  string
+	Flagged *bool
+	BookID  int64
+}
+
+type ReviewModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type ReviewStore interface {
+	Insert(context.Context, *Review) error
+	Get(context.Context, int64, int64) (*Review, error)
+	GetByID(context.Context, int64) (*Review, error)
+	GetAllForBook(context.Context, int64, Filters) ([]*Review, Metadata, error)
+	GetAllForModeration(context.Context, ReviewSearch, Filters) ([]*Review, Metadata, error)
+	Update(context.Context, *Review) error
+	Moderate(context.Context, *Review) error
+	Delete(context.Context, int64, int64) error
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	if !validator.ValueInRange(review.Rating, 1, 5) {
+		v.AddError("rating", "must be between 1 and 5")
+	}
+	if strings.TrimSpace(review.Body) == "" {
+		v.AddError("body", "must be provided")
+	} else if len(review.Body) > 5000 {
+		v.AddError("body", "cannot be more than 5000 bytes long")
+	}
+}
+
+func ValidateModerationReason(v *validator.Validator, status string, reason string) {
+	if status == ReviewStatusRejected && strings.TrimSpace(reason) == "" {
+		v.AddError("reason", "must be provided")
+		return
+	}
+	if len(reason) > 500 {
+		v.AddError("reason", "cannot be more than 500 bytes long")
+		return
+	}
+}
+
+// ContainsBannedWord reports whether any word of text matches one of the
+// banned words, ignoring case and punctuation.
+func ContainsBannedWord(text string, bannedWords []string) bool {
+	if len(bannedWords) < 1 {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		for _, banned := range bannedWords {
+			if word == strings.ToLower(banned) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m ReviewModel) Insert(ctx context.Context, review *Review) error {
+	query := `
+		INSERT INTO reviews (book_id, user_id, rating, body, status, flagged)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, version, created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		review.BookID,
+		review.UserID,
+		review.Rating,
+		review.Body,
+		review.Status,
+		review.Flagged,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&review.ID,
+		&review.Version,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "reviews_user_id_book_id_key") {
+			return ErrDuplicateReview
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m ReviewModel) Get(ctx context.Context, bookID int64, id int64) (*Review, error) {
+	if bookID < 1 || id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, book_id, user_id, rating, body, status, flagged, moderation_reason,
+            moderated_by, moderated_at, version, created_at, updated_at
+		FROM reviews
+		WHERE id = $1 AND book_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var review Review
+
+	err := m.DB.QueryRowContext(ctx, query, id, bookID).Scan(
+		&review.ID,
+		&review.BookID,
+		&review.UserID,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.Flagged,
+		&review.ModerationReason,
+		&review.ModeratedBy,
+		&review.ModeratedAt,
+		&review.Version,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (m ReviewModel) GetByID(ctx context.Context, id int64) (*Review, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, book_id, user_id, rating, body, status, flagged, moderation_reason,
+            moderated_by, moderated_at, version, created_at, updated_at
+		FROM reviews
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var review Review
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&review.ID,
+		&review.BookID,
+		&review.UserID,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.Flagged,
+		&review.ModerationReason,
+		&review.ModeratedBy,
+		&review.ModeratedAt,
+		&review.Version,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (m ReviewModel) GetAllForBook(
+	ctx context.Context,
+	bookID int64,
+	filters Filters,
+) ([]*Review, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), id, book_id, user_id, rating, body, status, flagged,
+            moderation_reason, moderated_by, moderated_at, version, created_at, updated_at
+		FROM reviews
+		WHERE book_id = $1 AND status = 'approved'
+		ORDER BY %s %s, id ASC
+		LIMIT $2 OFFSET $3`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, bookID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	reviews := []*Review{}
+	totalRecords := 0
+
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.BookID,
+			&review.UserID,
+			&review.Rating,
+			&review.Body,
+			&review.Status,
+			&review.Flagged,
+			&review.ModerationReason,
+			&review.ModeratedBy,
+			&review.ModeratedAt,
+			&review.Version,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, metadata, nil
+}
+
+func (m ReviewModel) GetAllForModeration(
+	ctx context.Context,
+	search ReviewSearch,
+	filters Filters,
+) ([]*Review, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), id, book_id, user_id, rating, body, status, flagged,
+            moderation_reason, moderated_by, moderated_at, version, created_at, updated_at
+		FROM reviews
+		WHERE status = $1
+        AND (flagged = $2 OR $2 IS NULL)
+        AND (book_id = $3 OR $3 = 0)
+		ORDER BY %s %s, id ASC
+		LIMIT $4 OFFSET $5`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		search.Status,
+		search.Flagged,
+		search.BookID,
+		filters.limit(),
+		filters.offset(),
+	}
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	reviews := []*Review{}
+	totalRecords := 0
+
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.BookID,
+			&review.UserID,
+			&review.Rating,
+			&review.Body,
+			&review.Status,
+			&review.Flagged,
+			&review.ModerationReason,
+			&review.ModeratedBy,
+			&review.ModeratedAt,
+			&review.Version,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, metadata, nil
+}
+
+func (m ReviewModel) Update(ctx context.Context, review *Review) error {
+	query := `
+		UPDATE reviews
+		SET rating = $3, body = $4, status = $5, flagged = $6, moderation_reason = $7,
+            moderated_by = $8, moderated_at = $9, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		review.ID,
+		review.Version,
+		review.Rating,
+		review.Body,
+		review.Status,
+		review.Flagged,
+		review.ModerationReason,
+		review.ModeratedBy,
+		review.ModeratedAt,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&review.Version, &review.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Moderate records the status, reason and moderator of a review. It doesn't
+// touch updated_at, which tracks changes made by the author.
+func (m ReviewModel) Moderate(ctx context.Context, review *Review) error {
+	query := `
+		UPDATE reviews
+		SET status = $3, moderation_reason = $4, moderated_by = $5, moderated_at = NOW(),
+            version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version, moderated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		review.ID,
+		review.Version,
+		review.Status,
+		review.ModerationReason,
+		review.ModeratedBy,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&review.Version, &review.ModeratedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m ReviewModel) Delete(ctx context.Context, bookID int64, id int64) error {
+	if bookID < 1 || id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM reviews
+		WHERE id = $1 AND book_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func TestValidateReview(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		review  Review
+		key     string
+		wantErr string
+	}{
+		{
+			name:    "rating_too_low",
+			review:  Review{Rating: 0, Body: "Good."},
+			key:     "rating",
+			wantErr: "must be between 1 and 5",
+		},
+		{
+			name:    "rating_too_high",
+			review:  Review{Rating: 6, Body: "Good."},
+			key:     "rating",
+			wantErr: "must be between 1 and 5",
+		},
+		{
+			name:    "empty_body",
+			review:  Review{Rating: 3, Body: " "},
+			key:     "body",
+			wantErr: "must be provided",
+		},
+		{
+			name:    "long_body",
+			review:  Review{Rating: 3, Body: strings.Repeat("a", 5001)},
+			key:     "body",
+			wantErr: "cannot be more than 5000 bytes long",
+		},
+		{
+			name:   "success",
+			review: Review{Rating: 5, Body: "Good."},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validator.NewValidator()
+			ValidateReview(v, &tc.review)
+
+			if tc.wantErr == "" {
+				if !v.IsValid() {
+					t.Fatalf("expected review validation to be successful; got %v", v.Errors)
+				}
+				return
+			}
+
+			if err := v.Errors[tc.key]; err != tc.wantErr {
+				t.Errorf("expected %s error to be %s; got %s", tc.key, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestContainsBannedWord(t *testing.T) {
+	t.Parallel()
+
+	bannedWords := []string{"spoiler", "Scam"}
+
+	testCases := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "no_banned_words", text: "A great read.", want: false},
+		{name: "banned_word", text: "This is a scam!", want: true},
+		{name: "different_case", text: "SPOILER: it ends well.", want: true},
+		{name: "partial_word", text: "Spoilers everywhere.", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ContainsBannedWord(tc.text, bannedWords); got != tc.want {
+				t.Errorf("expected banned word check to be %t; got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	ScopeActivation     = "activation"
+	ScopeAuthentication = "authentication"
+	ScopePasswordReset  = "password-reset"
+	ScopeTwoFactor      = "two-factor"
+)
+
+// Token is a credential of a user for a single scope. TwoFactor is set on
+// authentication tokens issued after a second factor was verified.
+type Token struct {
+	Plaintext string    `json:"token"`
+	Hash      []byte    `json:"-"`
+	UserID    int64     `json:"-"`
+	Expiry    time.Time `json:"expiry"`
+	Scope     string    `json:"-"`
+	TwoFactor bool      `json:"-"`
+}
+
+type TokenModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type TokenStore interface {
+	New(context.Context, int64, time.Duration, string) (*Token, error)
+	Insert(context.Context, *Token) error
+	DeleteAllForUser(context.Context, string, int64) error
+	RecordFailedAttempt(context.Context, string, string, int) error
+}
+
+func GenerateToken(userID int64, ttl time.Duration, scope string) (*Token, error) {
+	token := &Token{
+		UserID: userID,
+		Expiry: time.Now().Add(ttl),
+		Scope:  scope,
+	}
+
+	randomBytes := make([]byte, 16)
+
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+
+	token.Plaintext = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
+	token.Hash = HashToken(token.Plaintext)
+
+	return token, nil
+}
+
+func HashToken(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+func ValidateTokenPlaintext(v *validator.Validator, plaintext string) {
+	if plaintext == "" {
+		v.AddError("token", "must be provided")
+		return
+	}
+	if len(plaintext) != 26 {
+		v.AddError("token", "must be 26 bytes long")
+		return
+	}
+}
+
+func (m TokenModel) New(ctx context.Context, userID int64, ttl time.Duration, scope string) (*Token, error) {
+	token, err := GenerateToken(userID, ttl, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.Insert(ctx, token)
+	return token, err
+}
+
+func (m TokenModel) Insert(ctx context.Context, token *Token) error {
+	query := `
+		INSERT INTO tokens (hash, user_id, expiry, scope, two_factor)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{token.Hash, token.UserID, token.Expiry, token.Scope, token.TwoFactor}
+
+	_, err := m.DB.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (m TokenModel) DeleteAllForUser(ctx context.Context, scope string, userID int64) error {
+	query := `
+		DELETE FROM tokens
+		WHERE scope = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, scope, userID)
+	return err
+}
+
+// RecordFailedAttempt counts a failed attempt to use a token, and deletes the
+// token once maxAttempts is reached so it can't be used to guess codes.
+func (m TokenModel) RecordFailedAttempt(ctx context.Context, scope string, plaintext string, maxAttempts int) error {
+	query := `
+		UPDATE tokens
+		SET attempts = attempts + 1
+		WHERE hash = $1 AND scope = $2
+		RETURNING attempts`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	hash := HashToken(plaintext)
+
+	var attempts int
+
+	if err := m.DB.QueryRowContext(ctx, query, hash, scope).Scan(&attempts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if attempts < maxAttempts {
+		return nil
+	}
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM tokens WHERE hash = $1 AND scope = $2`, hash, scope)
+	return err
+}
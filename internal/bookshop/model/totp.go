@@ -0,0 +1,203 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+const recoveryCodesCount = 10
+
+type TOTP struct {
+	UserID    int64     `json:"-"`
+	Secret    string    `json:"-"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type TOTPModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type TOTPStore interface {
+	Get(context.Context, int64) (*TOTP, error)
+	Upsert(context.Context, *TOTP) error
+	Enable(context.Context, int64, []string) error
+	UseRecoveryCode(context.Context, int64, string) (bool, error)
+	UseCounter(context.Context, int64, int64) (bool, error)
+}
+
+// GenerateRecoveryCodes returns plaintext recovery codes in the xxxxx-xxxxx
+// format. Only their hashes are stored, so they must be shown to the user once.
+func GenerateRecoveryCodes() ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		randomBytes := make([]byte, 10)
+
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(randomBytes))
+		codes = append(codes, code[:5]+"-"+code[5:10])
+	}
+
+	return codes, nil
+}
+
+func (m TOTPModel) Get(ctx context.Context, userID int64) (*TOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled, created_at, updated_at
+		FROM users_totp
+		WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var t TOTP
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&t.UserID,
+		&t.Secret,
+		&t.Enabled,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (m TOTPModel) Upsert(ctx context.Context, t *TOTP) error {
+	query := `
+		INSERT INTO users_totp (user_id, secret, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled = EXCLUDED.enabled, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, t.UserID, t.Secret, t.Enabled).Scan(
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+}
+
+// Enable turns on two-factor authentication for a user and replaces their
+// recovery codes. The authentication tokens of the user are deleted, since they
+// were issued with the password alone.
+func (m TOTPModel) Enable(ctx context.Context, userID int64, recoveryCodes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE users_totp
+		SET enabled = true, updated_at = NOW()
+		WHERE user_id = $1`
+
+	result, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	query = `
+		DELETE FROM tokens
+		WHERE scope = $1 AND user_id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, ScopeAuthentication, userID); err != nil {
+		return err
+	}
+
+	for _, code := range recoveryCodes {
+		query := `
+			INSERT INTO recovery_codes (hash, user_id)
+			VALUES ($1, $2)`
+
+		if _, err := tx.ExecContext(ctx, query, HashToken(code), userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m TOTPModel) UseRecoveryCode(ctx context.Context, userID int64, plaintext string) (bool, error) {
+	query := `
+		DELETE FROM recovery_codes
+		WHERE hash = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, HashToken(strings.ToLower(plaintext)), userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UseCounter records the counter of a code accepted for a user. It returns
+// false when a code of the same or a later period was already accepted, which
+// means the code is being replayed.
+func (m TOTPModel) UseCounter(ctx context.Context, userID int64, counter int64) (bool, error) {
+	query := `
+		UPDATE users_totp
+		SET last_counter = $2, updated_at = NOW()
+		WHERE user_id = $1 AND last_counter < $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, counter)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != recoveryCodesCount {
+		t.Fatalf("expected %d recovery codes; got %d", recoveryCodesCount, len(codes))
+	}
+
+	rx := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+	seen := make(map[string]bool)
+
+	for _, code := range codes {
+		if !rx.MatchString(code) {
+			t.Errorf("expected recovery code %s to match %s", code, rx)
+		}
+		if seen[code] {
+			t.Errorf("expected recovery code %s to be unique", code)
+		}
+		seen[code] = true
+	}
+}
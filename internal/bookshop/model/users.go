@@ -0,0 +1,317 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+var ErrDuplicateEmail = errors.New("duplicate email")
+
+var AnonymousUser = &User{}
+
+// User is a bookshop account. TwoFactor is only set on users looked up by a
+// token, and reports whether that token was issued after a second factor was
+// verified.
+type User struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Password  Password  `json:"-"`
+	Activated bool      `json:"activated"`
+	Version   int32     `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	TwoFactor bool      `json:"-"`
+}
+
+func (u *User) IsAnonymous() bool {
+	return u == AnonymousUser
+}
+
+type Password struct {
+	plaintext *string
+	hash      []byte
+}
+
+func (p *Password) Set(plaintext string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), 12)
+	if err != nil {
+		return err
+	}
+
+	p.plaintext = &plaintext
+	p.hash = hash
+
+	return nil
+}
+
+func (p *Password) Matches(plaintext string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(p.hash, []byte(plaintext))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+type UserModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+type UserStore interface {
+	Register(context.Context, *User, *Token, ...string) error
+	GetByEmail(context.Context, string) (*User, error)
+	GetForToken(context.Context, string, string) (*User, error)
+	Update(context.Context, *User) error
+	ResetPassword(context.Context, *User) error
+}
+
+func ValidateEmail(v *validator.Validator, email string) {
+	if email == "" {
+		v.AddError("email", "must be provided")
+		return
+	}
+	if !validator.Matches(email, validator.EmailRX) {
+		v.AddError("email", "must be a valid email address")
+		return
+	}
+}
+
+func ValidatePasswordPlaintext(v *validator.Validator, password string) {
+	if password == "" {
+		v.AddError("password", "must be provided")
+		return
+	}
+	if len(password) < 8 {
+		v.AddError("password", "must be at least 8 bytes long")
+		return
+	}
+	if len(password) > 72 {
+		v.AddError("password", "cannot be more than 72 bytes long")
+		return
+	}
+}
+
+func ValidateUser(v *validator.Validator, user *User) {
+	validateUserName(v, user.Name)
+	ValidateEmail(v, user.Email)
+
+	if user.Password.plaintext != nil {
+		ValidatePasswordPlaintext(v, *user.Password.plaintext)
+	}
+
+	// A missing password hash means the password was never set, which is a
+	// programming error rather than a client input error.
+	if user.Password.hash == nil {
+		panic("missing password hash for user")
+	}
+}
+
+func validateUserName(v *validator.Validator, name string) {
+	if strings.TrimSpace(name) == "" {
+		v.AddError("name", "must be provided")
+		return
+	}
+	if len(name) > 500 {
+		v.AddError("name", "cannot be more than 500 bytes long")
+		return
+	}
+}
+
+// Register inserts a new user along with their permissions and activation
+// token in one transaction, so a failure midway can't leave an account that
+// can neither be activated nor registered again.
+func (m UserModel) Register(ctx context.Context, user *User, activation *Token, permissions ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO users (name, email, password_hash, activated)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, version, created_at, updated_at`
+
+		args := []interface{}{user.Name, user.Email, user.Password.hash, user.Activated}
+
+		err := tx.QueryRowContext(ctx, query, args...).Scan(
+			&user.ID,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			if isUniqueViolation(err, "users_email_key") {
+				return ErrDuplicateEmail
+			}
+			return err
+		}
+
+		query = `
+			INSERT INTO users_permissions
+			SELECT $1, permissions.id FROM permissions WHERE permissions.code = ANY($2)`
+
+		if _, err := tx.ExecContext(ctx, query, user.ID, pq.Array(permissions)); err != nil {
+			return err
+		}
+
+		activation.UserID = user.ID
+
+		query = `
+			INSERT INTO tokens (hash, user_id, expiry, scope)
+			VALUES ($1, $2, $3, $4)`
+
+		args = []interface{}{activation.Hash, activation.UserID, activation.Expiry, activation.Scope}
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+// ResetPassword saves the new password of a user and deletes their password
+// reset tokens and sessions in one transaction, so a used reset token or an old
+// session can't outlive the password change.
+func (m UserModel) ResetPassword(ctx context.Context, user *User) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	return withTransaction(ctx, m.DB, func(tx *sql.Tx) error {
+		query := `
+			UPDATE users
+			SET password_hash = $3, version = version + 1, updated_at = NOW()
+			WHERE id = $1 AND version = $2
+			RETURNING version, updated_at`
+
+		err := tx.QueryRowContext(ctx, query, user.ID, user.Version, user.Password.hash).Scan(
+			&user.Version,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEditConflict
+			}
+			return err
+		}
+
+		query = `
+			DELETE FROM tokens
+			WHERE user_id = $1 AND scope = ANY($2)`
+
+		scopes := []string{ScopePasswordReset, ScopeAuthentication, ScopeTwoFactor}
+
+		_, err = tx.ExecContext(ctx, query, user.ID, pq.Array(scopes))
+		return err
+	})
+}
+
+func (m UserModel) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+		SELECT id, name, email, password_hash, activated, version, created_at, updated_at
+		FROM users
+		WHERE email = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	var user User
+
+	err := m.DB.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Password.hash,
+		&user.Activated,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (m UserModel) GetForToken(ctx context.Context, scope string, plaintext string) (*User, error) {
+	query := `
+		SELECT users.id, users.name, users.email, users.password_hash, users.activated,
+            users.version, users.created_at, users.updated_at, tokens.two_factor
+		FROM users
+		INNER JOIN tokens ON users.id = tokens.user_id
+		WHERE tokens.hash = $1 AND tokens.scope = $2 AND tokens.expiry > $3`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{HashToken(plaintext), scope, time.Now()}
+
+	var user User
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Password.hash,
+		&user.Activated,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.TwoFactor,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (m UserModel) Update(ctx context.Context, user *User) error {
+	query := `
+		UPDATE users
+		SET name = $3, email = $4, password_hash = $5, activated = $6,
+            version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	args := []interface{}{
+		user.ID,
+		user.Version,
+		user.Name,
+		user.Email,
+		user.Password.hash,
+		user.Activated,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Version, &user.UpdatedAt)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "users_email_key"):
+			return ErrDuplicateEmail
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
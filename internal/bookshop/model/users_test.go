@@ -0,0 +1,106 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+func TestPasswordMatches(t *testing.T) {
+	t.Parallel()
+
+	var p Password
+
+	if err := p.Set("pa55word"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := p.Matches("pa55word")
+	if err != nil || !ok {
+		t.Errorf("expected password to match; got %t, %v", ok, err)
+	}
+
+	ok, err = p.Matches("wrong-password")
+	if err != nil || ok {
+		t.Errorf("expected password not to match; got %t, %v", ok, err)
+	}
+}
+
+func TestValidateUser(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		userName string
+		email    string
+		password string
+		key      string
+		wantErr  string
+	}{
+		{
+			name:     "empty_name",
+			email:    "test@example.com",
+			password: "pa55word",
+			key:      "name",
+			wantErr:  "must be provided",
+		},
+		{
+			name:     "invalid_email",
+			userName: "Test",
+			email:    "test@",
+			password: "pa55word",
+			key:      "email",
+			wantErr:  "must be a valid email address",
+		},
+		{
+			name:     "short_password",
+			userName: "Test",
+			email:    "test@example.com",
+			password: "pass",
+			key:      "password",
+			wantErr:  "must be at least 8 bytes long",
+		},
+		{
+			name:     "long_password",
+			userName: "Test",
+			email:    "test@example.com",
+			password: strings.Repeat("a", 73),
+			key:      "password",
+			wantErr:  "cannot be more than 72 bytes long",
+		},
+		{
+			name:     "success",
+			userName: "Test",
+			email:    "test@example.com",
+			password: "pa55word",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := &User{Name: tc.userName, Email: tc.email}
+
+			// Hashing is skipped on purpose to keep the test fast, since only
+			// the plaintext password is validated.
+			user.Password.plaintext = &tc.password
+			user.Password.hash = []byte("hash")
+
+			v := validator.NewValidator()
+			ValidateUser(v, user)
+
+			if tc.wantErr == "" {
+				if !v.IsValid() {
+					t.Fatalf("expected user validation to be successful; got %v", v.Errors)
+				}
+				return
+			}
+
+			if err := v.Errors[tc.key]; err != tc.wantErr {
+				t.Errorf("expected %s error to be %s; got %s", tc.key, tc.wantErr, err)
+			}
+		})
+	}
+}
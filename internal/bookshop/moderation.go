@@ -0,0 +1,151 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+type listModerationReviewsResponse struct {
+	Code     int             `json:"code"`
+	Reviews  []*model.Review `json:"reviews"`
+	Metadata model.Metadata  `json:"metadata"`
+}
+
+func (b *Bookshop) listModerationReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		model.ReviewSearch
+		model.Filters
+	}
+
+	q := r.URL.Query()
+	v := validator.NewValidator()
+
+	input.Status = b.readString(q, "status", model.ReviewStatusPending)
+	input.Flagged = b.readBool(q, "flagged", v)
+	input.BookID = int64(b.readInt(q, "book_id", 0, v))
+
+	input.Filters.Page = b.readInt(q, "page", 1, v)
+	input.Filters.PageSize = b.readInt(q, "page_size", 10, v)
+
+	// Oldest reviews first, so the queue is worked through in order.
+	input.Filters.Sort = b.readString(q, "sort", "created_at")
+	input.Filters.SortSafeList = []string{
+		"id", "rating", "created_at",
+		"-id", "-rating", "-created_at",
+	}
+
+	statuses := []string{
+		model.ReviewStatusPending,
+		model.ReviewStatusApproved,
+		model.ReviewStatusRejected,
+	}
+
+	if !validator.ValueInList(input.Status, statuses...) {
+		v.AddError("status", "invalid status value")
+	}
+
+	if input.BookID < 0 {
+		v.AddError("book_id", "must be greater than 0")
+	}
+
+	if input.Filters.Validate(v); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	reviews, metadata, err := b.models.Reviews.GetAllForModeration(r.Context(), input.ReviewSearch, input.Filters)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := listModerationReviewsResponse{
+		Code:     http.StatusOK,
+		Reviews:  reviews,
+		Metadata: metadata,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+func (b *Bookshop) approveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	b.moderateReview(w, r, model.ReviewStatusApproved)
+}
+
+func (b *Bookshop) rejectReviewHandler(w http.ResponseWriter, r *http.Request) {
+	b.moderateReview(w, r, model.ReviewStatusRejected)
+}
+
+type moderateReviewResponse struct {
+	Code   int           `json:"code"`
+	Review *model.Review `json:"review"`
+}
+
+// moderateReview moves a review to the given status, recording the reason and
+// the staff user that made the decision. A reason is required to reject.
+func (b *Bookshop) moderateReview(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	review, err := b.models.Reviews.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if model.ValidateModerationReason(v, status, input.Reason); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	moderatorID := b.contextGetUser(r).ID
+
+	review.Status = status
+	review.ModerationReason = input.Reason
+	review.ModeratedBy = &moderatorID
+
+	if err := b.models.Reviews.Moderate(r.Context(), review); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := moderateReviewResponse{
+		Code:   http.StatusOK,
+		Review: review,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,162 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+func TestListModerationReviewsHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name      string
+		query     string
+		headers   http.Header
+		wantCode  int
+		wantCount int
+	}{
+		{
+			name:     "customer user",
+			query:    "",
+			headers:  customerHeaders(),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "invalid status",
+			query:    "?status=deleted",
+			headers:  staffHeaders(),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid flagged",
+			query:    "?flagged=maybe",
+			headers:  staffHeaders(),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "pending reviews",
+			query:     "",
+			headers:   staffHeaders(),
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+		},
+		{
+			name:      "pending reviews not flagged",
+			query:     "?flagged=false",
+			headers:   staffHeaders(),
+			wantCode:  http.StatusOK,
+			wantCount: 0,
+		},
+		{
+			name:      "approved reviews",
+			query:     "?status=approved&book_id=1",
+			headers:   staffHeaders(),
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodGet, "/api/v1/moderation/reviews"+tc.query, "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res listModerationReviewsResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Reviews) != tc.wantCount {
+				t.Errorf("expected %d reviews; got %d", tc.wantCount, len(res.Reviews))
+			}
+		})
+	}
+}
+
+func TestModerateReviewHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name       string
+		path       string
+		body       string
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:     "review not found",
+			path:     "/api/v1/moderation/reviews/3/approve",
+			body:     `{}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "reject without reason",
+			path:     "/api/v1/moderation/reviews/2/reject",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:       "reject",
+			path:       "/api/v1/moderation/reviews/2/reject",
+			body:       `{"reason": "Contains spoilers."}`,
+			wantCode:   http.StatusOK,
+			wantStatus: model.ReviewStatusRejected,
+		},
+		{
+			name:       "approve",
+			path:       "/api/v1/moderation/reviews/2/approve",
+			body:       `{}`,
+			wantCode:   http.StatusOK,
+			wantStatus: model.ReviewStatusApproved,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, tc.path, tc.body, staffHeaders())
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res moderateReviewResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Review.Status != tc.wantStatus {
+				t.Errorf("expected review status to be %s; got %s", tc.wantStatus, res.Review.Status)
+			}
+
+			if res.Review.ModeratedBy == nil {
+				t.Error("expected review moderator to be recorded")
+			}
+		})
+	}
+}
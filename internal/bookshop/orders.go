@@ -0,0 +1,330 @@
+package bookshop
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+type createOrderResponse struct {
+	Code  int          `json:"code"`
+	Order *model.Order `json:"order"`
+}
+
+func (b *Bookshop) createOrderHandler(w http.ResponseWriter, r *http.Request) {
+	order, err := b.models.Orders.CreateFromCart(r.Context(), b.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEmptyCart):
+			v := validator.NewValidator()
+			v.AddError("cart", "must contain at least 1 book")
+			b.validationError(w, r, v.Errors)
+		case errors.Is(err, model.ErrInsufficientStock):
+			b.insufficientStock(w, r)
+		default:
+			b.serverError(w, r, err)
+		}
+		return
+	}
+
+	res := createOrderResponse{
+		Code:  http.StatusCreated,
+		Order: order,
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/api/v1/orders/%d", order.ID))
+
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type showOrderResponse struct {
+	Code  int          `json:"code"`
+	Order *model.Order `json:"order"`
+}
+
+func (b *Bookshop) showOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	order, err := b.models.Orders.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	// Orders of other users are reported as missing rather than forbidden, so
+	// order ids can't be probed.
+	if order.UserID != b.contextGetUser(r).ID {
+		b.notFound(w, r)
+		return
+	}
+
+	res := showOrderResponse{
+		Code:  http.StatusOK,
+		Order: order,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type payOrderResponse struct {
+	Code  int          `json:"code"`
+	Order *model.Order `json:"order"`
+}
+
+// payOrderHandler authorizes and captures the total of a pending order with
+// the payment provider. Declined payments leave the order pending so it can be
+// paid with another payment method, while payments declined at capture fail the
+// order and release its stock.
+func (b *Bookshop) payOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	var input struct {
+		PaymentToken string `json:"payment_token"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if input.PaymentToken == "" {
+		v.AddError("payment_token", "must be provided")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	order, err := b.models.Orders.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if order.UserID != b.contextGetUser(r).ID {
+		b.notFound(w, r)
+		return
+	}
+
+	// Orders holding a payment are settled by the provider webhooks, even if
+	// capturing it failed midway.
+	if order.Status != model.OrderStatusPending || order.PaymentID != "" {
+		b.invalidOrderStatus(w, r, "The order has already been paid or is being paid.")
+		return
+	}
+
+	// The stock of orders past their reservation may be sold to others at any
+	// time, so they are left to expire.
+	if time.Now().After(order.ReservedUntil) {
+		b.invalidOrderStatus(w, r, "The order reservation has expired, please check out again.")
+		return
+	}
+
+	payment, err := b.payments.Authorize(r.Context(), payments.AuthorizeRequest{
+		OrderID:  order.ID,
+		Amount:   order.Total.Amount,
+		Currency: order.Total.Currency,
+		Token:    input.PaymentToken,
+	})
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if payment.Status == payments.StatusFailed {
+		b.paymentDeclined(w, r, payment.FailureReason)
+		return
+	}
+
+	order.PaymentProvider = b.payments.Name()
+	order.PaymentID = payment.ID
+
+	if err := b.models.Orders.SetPayment(r.Context(), order); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	// A capture that errors may still have gone through at the provider, so
+	// the order is left pending with its payment for the webhooks to settle.
+	payment, err = b.payments.Capture(r.Context(), payment.ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	status := model.OrderStatusPaid
+	if payment.Status == payments.StatusFailed {
+		status = model.OrderStatusFailed
+	}
+
+	if err := b.models.Orders.Transition(r.Context(), order, status); err != nil {
+		if !errors.Is(err, model.ErrInvalidStatusTransition) {
+			b.serverError(w, r, err)
+			return
+		}
+
+		// A webhook settled the order first, so report its current state.
+		if order, err = b.models.Orders.Get(r.Context(), order.ID); err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+	}
+
+	if order.Status == model.OrderStatusFailed {
+		b.paymentDeclined(w, r, payment.FailureReason)
+		return
+	}
+
+	res := payOrderResponse{
+		Code:  http.StatusOK,
+		Order: order,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type cancelOrderResponse struct {
+	Code  int          `json:"code"`
+	Order *model.Order `json:"order"`
+}
+
+// cancelOrderHandler lets users give up on their pending orders, failing them
+// and releasing their stock right away instead of when their reservation
+// expires.
+func (b *Bookshop) cancelOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	order, err := b.models.Orders.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if order.UserID != b.contextGetUser(r).ID {
+		b.notFound(w, r)
+		return
+	}
+
+	if err := b.models.Orders.Cancel(r.Context(), order); err != nil {
+		if errors.Is(err, model.ErrInvalidStatusTransition) {
+			b.invalidOrderStatus(w, r, "Only pending orders that are not being paid can be canceled.")
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := cancelOrderResponse{
+		Code:  http.StatusOK,
+		Order: order,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type refundOrderResponse struct {
+	Code  int          `json:"code"`
+	Order *model.Order `json:"order"`
+}
+
+func (b *Bookshop) refundOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	order, err := b.models.Orders.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if order.Status != model.OrderStatusPaid || order.PaymentProvider != b.payments.Name() {
+		b.invalidOrderStatus(w, r, "Only orders paid with the current payment provider can be refunded.")
+		return
+	}
+
+	if _, err := b.payments.Refund(r.Context(), order.PaymentID); err != nil {
+		if errors.Is(err, payments.ErrInvalidPaymentState) {
+			b.invalidOrderStatus(w, r, "The payment of the order can't be refunded.")
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err := b.models.Orders.Transition(r.Context(), order, model.OrderStatusRefunded); err != nil {
+		if !errors.Is(err, model.ErrInvalidStatusTransition) {
+			b.serverError(w, r, err)
+			return
+		}
+
+		if order, err = b.models.Orders.Get(r.Context(), order.ID); err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+	}
+
+	res := refundOrderResponse{
+		Code:  http.StatusOK,
+		Order: order,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
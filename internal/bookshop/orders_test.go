@@ -0,0 +1,409 @@
+package bookshop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+)
+
+func TestCreateOrderHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			headers:  nil,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "empty cart",
+			headers:  staffHeaders(),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  customerHeaders(),
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/orders", "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+
+			var res createOrderResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			wantTotal := "20.00"
+			if res.Order.Total.String() != wantTotal {
+				t.Errorf("expected order total to be %s; got %s", wantTotal, res.Order.Total)
+			}
+
+			if !res.Order.ReservedUntil.After(time.Now()) {
+				t.Errorf("expected order to be reserved until a later time; got %s", res.Order.ReservedUntil)
+			}
+		})
+	}
+}
+
+func TestShowOrderHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "order not found",
+			id:       "3",
+			headers:  customerHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "order of another user",
+			id:       "1",
+			headers:  staffHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			headers:  customerHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodGet, "/api/v1/orders/"+tc.id, "", tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestPayOrderHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name       string
+		id         string
+		body       string
+		headers    http.Header
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:     "anonymous user",
+			id:       "1",
+			body:     `{"payment_token": "tok_approved"}`,
+			headers:  nil,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "missing payment token",
+			id:       "1",
+			body:     `{}`,
+			headers:  customerHeaders(),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "order of another user",
+			id:       "1",
+			body:     `{"payment_token": "tok_approved"}`,
+			headers:  staffHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "order already paid",
+			id:       "2",
+			body:     `{"payment_token": "tok_approved"}`,
+			headers:  staffHeaders(),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "payment declined",
+			id:       "1",
+			body:     `{"payment_token": "tok_declined"}`,
+			headers:  customerHeaders(),
+			wantCode: http.StatusPaymentRequired,
+		},
+		{
+			name:     "capture failed",
+			id:       "1",
+			body:     `{"payment_token": "tok_capture_fails"}`,
+			headers:  customerHeaders(),
+			wantCode: http.StatusPaymentRequired,
+		},
+		{
+			name:       "success",
+			id:         "1",
+			body:       `{"payment_token": "tok_approved"}`,
+			headers:    customerHeaders(),
+			wantCode:   http.StatusOK,
+			wantStatus: model.OrderStatusPaid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/orders/"+tc.id+"/payment", tc.body, tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res payOrderResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Order.Status != tc.wantStatus {
+				t.Errorf("expected order status to be %s; got %s", tc.wantStatus, res.Order.Status)
+			}
+
+			if res.Order.PaymentID == "" {
+				t.Error("expected order payment id to be set")
+			}
+		})
+	}
+}
+
+// captureErrorProvider is a payment provider whose captures never reach the
+// provider.
+type captureErrorProvider struct {
+	*payments.FakeProvider
+}
+
+func (p captureErrorProvider) Capture(ctx context.Context, paymentID string) (*payments.Payment, error) {
+	return nil, errors.New("capture request timed out")
+}
+
+// transitionRecorder records the statuses orders are moved to.
+type transitionRecorder struct {
+	model.OrderStore
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (r *transitionRecorder) Transition(ctx context.Context, order *model.Order, status string) error {
+	r.mu.Lock()
+	r.statuses = append(r.statuses, status)
+	r.mu.Unlock()
+	return r.OrderStore.Transition(ctx, order, status)
+}
+
+func TestPayOrderHandlerCaptureError(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.payments = captureErrorProvider{FakeProvider: payments.NewFakeProvider(testWebhookSecret)}
+
+	orders := &transitionRecorder{OrderStore: app.models.Orders}
+	app.models.Orders = orders
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	body := `{"payment_token": "tok_approved"}`
+
+	code, _ := srv.request(t, http.MethodPost, "/api/v1/orders/1/payment", body, customerHeaders())
+	if code != http.StatusInternalServerError {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusInternalServerError, code)
+	}
+
+	if len(orders.statuses) != 0 {
+		t.Errorf("expected order to be left pending; got transitions %v", orders.statuses)
+	}
+}
+
+func TestCancelOrderHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		id       string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			id:       "1",
+			headers:  nil,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "order of another user",
+			id:       "1",
+			headers:  staffHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "order already paid",
+			id:       "2",
+			headers:  staffHeaders(),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "success",
+			id:       "1",
+			headers:  customerHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/orders/"+tc.id+"/cancel", "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res cancelOrderResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Order.Status != model.OrderStatusFailed {
+				t.Errorf("expected order status to be %s; got %s", model.OrderStatusFailed, res.Order.Status)
+			}
+		})
+	}
+}
+
+func TestRefundOrderHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	// Take the payment of the mocked paid order.
+	payment, err := app.payments.Authorize(context.Background(), payments.AuthorizeRequest{
+		OrderID:  2,
+		Amount:   2000,
+		Currency: model.DefaultCurrency,
+		Token:    payments.FakeTokenApproved,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.payments.Capture(context.Background(), payment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		id       string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "missing permission",
+			id:       "2",
+			headers:  customerHeaders(),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "order not found",
+			id:       "3",
+			headers:  staffHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "order not paid",
+			id:       "1",
+			headers:  staffHeaders(),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "success",
+			id:       "2",
+			headers:  staffHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/orders/"+tc.id+"/refund", "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res refundOrderResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Order.Status != model.OrderStatusRefunded {
+				t.Errorf("expected order status to be %s; got %s", model.OrderStatusRefunded, res.Order.Status)
+			}
+		})
+	}
+}
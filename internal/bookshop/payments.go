@@ -0,0 +1,109 @@
+package bookshop
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/logging"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const maxWebhookBytes = 65_536
+
+// paymentEventStatuses maps the webhook events of a provider to the status
+// they move orders to.
+var paymentEventStatuses = map[string]string{
+	payments.EventPaymentCaptured: model.OrderStatusPaid,
+	payments.EventPaymentFailed:   model.OrderStatusFailed,
+	payments.EventPaymentRefunded: model.OrderStatusRefunded,
+}
+
+type paymentWebhookResponse struct {
+	Code int `json:"code"`
+}
+
+// paymentWebhookHandler applies the signed payment events sent by the
+// provider to their orders. Providers retry deliveries until they succeed, so
+// events for unknown payments and events already applied are acknowledged
+// without changing anything. Payments captured for orders that failed in the
+// meantime are refunded.
+func (b *Bookshop) paymentWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		v := validator.NewValidator()
+		v.AddError("body", "must not be larger than 65536 bytes")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	event, err := b.payments.VerifyWebhook(payload, r.Header)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			b.unauthorized(w, r, "Invalid or missing webhook signature.", nil)
+			return
+		}
+		v := validator.NewValidator()
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if status, ok := paymentEventStatuses[event.Type]; ok {
+		order, err := b.models.Orders.GetByPayment(r.Context(), b.payments.Name(), event.PaymentID)
+		if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+			b.serverError(w, r, err)
+			return
+		}
+
+		if order != nil {
+			err := b.models.Orders.Transition(r.Context(), order, status)
+			if err != nil && !errors.Is(err, model.ErrInvalidStatusTransition) {
+				b.serverError(w, r, err)
+				return
+			}
+
+			// Failed orders no longer hold their stock, so a payment captured
+			// for one is given back to the customer.
+			if err != nil && status == model.OrderStatusPaid && order.Status == model.OrderStatusFailed {
+				if err := b.refundFailedOrder(r, order); err != nil {
+					b.serverError(w, r, err)
+					return
+				}
+			}
+		}
+	}
+
+	res := paymentWebhookResponse{
+		Code: http.StatusOK,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+// refundFailedOrder refunds the payment captured for a failed order. Payments
+// the provider no longer holds as captured were refunded by an earlier
+// delivery of the event.
+func (b *Bookshop) refundFailedOrder(r *http.Request, order *model.Order) error {
+	logging.LoggerFromContext(r.Context()).Error(
+		"payment captured for a failed order, refunding it",
+		slog.Int64("order_id", order.ID),
+		slog.String("payment_id", order.PaymentID),
+	)
+
+	if _, err := b.payments.Refund(r.Context(), order.PaymentID); err != nil {
+		if errors.Is(err, payments.ErrInvalidPaymentState) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package bookshop
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/payments"
+)
+
+func TestPaymentWebhookHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	provider, ok := app.payments.(*payments.FakeProvider)
+	if !ok {
+		t.Fatalf("expected payments provider to be fake; got %T", app.payments)
+	}
+
+	signed := func(payload string, sentAt time.Time) http.Header {
+		headers := make(http.Header)
+		headers.Set(payments.FakeSignatureHeader, provider.SignWebhook([]byte(payload), sentAt))
+		return headers
+	}
+
+	refunded := `{"id": "evt_1", "type": "payment.refunded", "payment_id": "fake_pay_1"}`
+
+	testCases := []struct {
+		name     string
+		body     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "missing signature",
+			body:     refunded,
+			headers:  nil,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid signature",
+			body:     refunded,
+			headers:  signed(`{"id": "evt_2"}`, time.Now()),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "expired signature",
+			body:     refunded,
+			headers:  signed(refunded, time.Now().Add(-time.Hour)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid payload",
+			body:     "refunded",
+			headers:  signed("refunded", time.Now()),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown payment",
+			body:     `{"id": "evt_3", "type": "payment.captured", "payment_id": "fake_pay_9"}`,
+			headers:  signed(`{"id": "evt_3", "type": "payment.captured", "payment_id": "fake_pay_9"}`, time.Now()),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "event already applied",
+			body:     `{"id": "evt_4", "type": "payment.captured", "payment_id": "fake_pay_1"}`,
+			headers:  signed(`{"id": "evt_4", "type": "payment.captured", "payment_id": "fake_pay_1"}`, time.Now()),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "success",
+			body:     refunded,
+			headers:  signed(refunded, time.Now()),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodPost, "/api/v1/payments/webhook", tc.body, tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookHandlerRefundsFailedOrders(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	provider, ok := app.payments.(*payments.FakeProvider)
+	if !ok {
+		t.Fatalf("expected payments provider to be fake; got %T", app.payments)
+	}
+
+	// Capture the payments of the mocked orders, the second one being held by
+	// an order that failed before its capture went through.
+	for i := 0; i < 2; i++ {
+		payment, err := provider.Authorize(context.Background(), payments.AuthorizeRequest{
+			OrderID:  1,
+			Amount:   2000,
+			Currency: model.DefaultCurrency,
+			Token:    payments.FakeTokenApproved,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := provider.Capture(context.Background(), payment.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	captured := `{"id": "evt_1", "type": "payment.captured", "payment_id": "fake_pay_2"}`
+
+	headers := make(http.Header)
+	headers.Set(payments.FakeSignatureHeader, provider.SignWebhook([]byte(captured), time.Now()))
+
+	code, _ := srv.request(t, http.MethodPost, "/api/v1/payments/webhook", captured, headers)
+	if code != http.StatusOK {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusOK, code)
+	}
+
+	if _, err := provider.Refund(context.Background(), "fake_pay_2"); !errors.Is(err, payments.ErrInvalidPaymentState) {
+		t.Errorf("expected payment to be refunded already; got %v", err)
+	}
+
+	if _, err := provider.Refund(context.Background(), "fake_pay_1"); err != nil {
+		t.Errorf("expected payment of the paid order to be left captured; got %v", err)
+	}
+}
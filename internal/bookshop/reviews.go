@@ -0,0 +1,317 @@
+package bookshop
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+type listReviewsResponse struct {
+	Code     int             `json:"code"`
+	Reviews  []*model.Review `json:"reviews"`
+	Metadata model.Metadata  `json:"metadata"`
+}
+
+func (b *Bookshop) listReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	var input struct {
+		model.Filters
+	}
+
+	q := r.URL.Query()
+	v := validator.NewValidator()
+
+	input.Filters.Page = b.readInt(q, "page", 1, v)
+	input.Filters.PageSize = b.readInt(q, "page_size", 10, v)
+
+	input.Filters.Sort = b.readString(q, "sort", "-created_at")
+	input.Filters.SortSafeList = []string{
+		"id", "rating", "created_at",
+		"-id", "-rating", "-created_at",
+	}
+
+	if input.Filters.Validate(v); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if _, err := b.models.Books.Get(r.Context(), bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	reviews, metadata, err := b.models.Reviews.GetAllForBook(r.Context(), bookID, input.Filters)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := listReviewsResponse{
+		Code:     http.StatusOK,
+		Reviews:  reviews,
+		Metadata: metadata,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type showReviewResponse struct {
+	Code   int           `json:"code"`
+	Review *model.Review `json:"review"`
+}
+
+func (b *Bookshop) showReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, ok := b.readReview(w, r)
+	if !ok {
+		return
+	}
+
+	// Only approved reviews are public, but authors can follow their own.
+	if review.Status != model.ReviewStatusApproved && review.UserID != b.contextGetUser(r).ID {
+		b.notFound(w, r)
+		return
+	}
+
+	res := showReviewResponse{
+		Code:   http.StatusOK,
+		Review: review,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type createReviewResponse struct {
+	Code   int           `json:"code"`
+	Review *model.Review `json:"review"`
+}
+
+func (b *Bookshop) createReviewHandler(w http.ResponseWriter, r *http.Request) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return
+	}
+
+	if _, err := b.models.Books.Get(r.Context(), bookID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	var input struct {
+		Rating int    `json:"rating"`
+		Body   string `json:"body"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	// New reviews wait in the moderation queue, flagged when they contain a
+	// banned word.
+	review := &model.Review{
+		BookID:  bookID,
+		UserID:  b.contextGetUser(r).ID,
+		Rating:  input.Rating,
+		Body:    input.Body,
+		Status:  model.ReviewStatusPending,
+		Flagged: model.ContainsBannedWord(input.Body, b.config.BannedWords),
+	}
+
+	if model.ValidateReview(v, review); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if err := b.models.Reviews.Insert(r.Context(), review); err != nil {
+		if errors.Is(err, model.ErrDuplicateReview) {
+			v.AddError("review", "you have already reviewed this book")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := createReviewResponse{
+		Code:   http.StatusCreated,
+		Review: review,
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/api/v1/books/%d/reviews/%d", review.BookID, review.ID))
+
+	if err := jsontil.Marshal(w, res, res.Code, headers); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type updateReviewResponse struct {
+	Code   int           `json:"code"`
+	Review *model.Review `json:"review"`
+}
+
+func (b *Bookshop) updateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, ok := b.readReview(w, r)
+	if !ok {
+		return
+	}
+
+	if review.UserID != b.contextGetUser(r).ID {
+		b.forbidden(w, r, "You can only update your own reviews.")
+		return
+	}
+
+	var input struct {
+		Rating *int    `json:"rating"`
+		Body   *string `json:"body"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if input.Rating != nil {
+		review.Rating = *input.Rating
+	}
+	if input.Body != nil {
+		review.Body = *input.Body
+	}
+
+	// Edited reviews go back to the moderation queue.
+	review.Status = model.ReviewStatusPending
+	review.Flagged = model.ContainsBannedWord(review.Body, b.config.BannedWords)
+	review.ModerationReason = ""
+	review.ModeratedBy = nil
+	review.ModeratedAt = nil
+
+	if model.ValidateReview(v, review); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if err := b.models.Reviews.Update(r.Context(), review); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := updateReviewResponse{
+		Code:   http.StatusOK,
+		Review: review,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type deleteReviewResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// deleteReviewHandler lets users delete their own reviews, and staff with the
+// books:write permission delete any review.
+func (b *Bookshop) deleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, ok := b.readReview(w, r)
+	if !ok {
+		return
+	}
+
+	user := b.contextGetUser(r)
+
+	if review.UserID != user.ID {
+		permissions, err := b.models.Permissions.GetAllForUser(r.Context(), user.ID)
+		if err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+		if !permissions.Include(model.PermissionBooksWrite) {
+			b.forbidden(w, r, "You can only delete your own reviews.")
+			return
+		}
+	}
+
+	if err := b.models.Reviews.Delete(r.Context(), review.BookID, review.ID); err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := deleteReviewResponse{
+		Code:    http.StatusOK,
+		Message: "Review successfully deleted.",
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+// readReview loads the review identified by the book and review path values.
+// It writes the error response itself and reports whether the review was found.
+func (b *Bookshop) readReview(w http.ResponseWriter, r *http.Request) (*model.Review, bool) {
+	bookID, err := b.readIDParam(r)
+	if err != nil {
+		b.notFound(w, r)
+		return nil, false
+	}
+
+	reviewID, err := b.readInt64Param(r, "review_id")
+	if err != nil {
+		b.notFound(w, r)
+		return nil, false
+	}
+
+	review, err := b.models.Reviews.Get(r.Context(), bookID, reviewID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.notFound(w, r)
+			return nil, false
+		}
+		b.serverError(w, r, err)
+		return nil, false
+	}
+
+	return review, true
+}
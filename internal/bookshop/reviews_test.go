@@ -0,0 +1,291 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
+
+func TestListReviewsHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{
+			name:     "book not found",
+			path:     "/api/v1/books/2/reviews",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid sort parameter",
+			path:     "/api/v1/books/1/reviews?sort=body",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			path:     "/api/v1/books/1/reviews",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.get(t, tc.path)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res listReviewsResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Reviews) != 1 {
+				t.Errorf("expected list of reviews to have 1 review; got %d", len(res.Reviews))
+			}
+		})
+	}
+}
+
+func TestCreateReviewHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			headers:  nil,
+			body:     `{"rating": 5, "body": "Great book."}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid rating",
+			headers:  staffHeaders(),
+			body:     `{"rating": 6, "body": "Great book."}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "duplicate review",
+			headers:  customerHeaders(),
+			body:     `{"rating": 5, "body": "Great book."}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  staffHeaders(),
+			body:     `{"rating": 5, "body": "Great book."}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodPost, "/api/v1/books/1/reviews", tc.body, tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestCreateReviewHandlerFlagsBannedWords(t *testing.T) {
+	t.Parallel()
+
+	app := newTestBookshop(t)
+	app.config.BannedWords = []string{"spoiler"}
+
+	srv := newTestServer(t, app.Routes())
+	defer srv.Close()
+
+	body := `{"rating": 1, "body": "Huge SPOILER: the butler did it."}`
+
+	code, resBody := srv.request(t, http.MethodPost, "/api/v1/books/1/reviews", body, staffHeaders())
+	if code != http.StatusCreated {
+		t.Fatalf("expected status code to be %d; got %d", http.StatusCreated, code)
+	}
+
+	var res createReviewResponse
+
+	if err := json.Unmarshal([]byte(resBody), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Review.Status != model.ReviewStatusPending {
+		t.Errorf("expected review status to be %s; got %s", model.ReviewStatusPending, res.Review.Status)
+	}
+
+	if !res.Review.Flagged {
+		t.Error("expected review to be flagged")
+	}
+}
+
+func TestShowReviewHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "approved review",
+			path:     "/api/v1/books/1/reviews/1",
+			headers:  nil,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "pending review",
+			path:     "/api/v1/books/1/reviews/2",
+			headers:  customerHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "pending review of the author",
+			path:     "/api/v1/books/1/reviews/2",
+			headers:  staffHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodGet, tc.path, "", tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestUpdateReviewHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		path     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "review not found",
+			path:     "/api/v1/books/1/reviews/3",
+			headers:  customerHeaders(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "not the author",
+			path:     "/api/v1/books/1/reviews/1",
+			headers:  staffHeaders(),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "success",
+			path:     "/api/v1/books/1/reviews/1",
+			headers:  customerHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPatch, tc.path, `{"rating": 2}`, tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res updateReviewResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Review.Rating != 2 || res.Review.Version != 2 {
+				t.Errorf("expected review rating 2 and version 2; got %d and %d",
+					res.Review.Rating, res.Review.Version)
+			}
+
+			if res.Review.Status != model.ReviewStatusPending {
+				t.Errorf("expected review status to be %s; got %s", model.ReviewStatusPending, res.Review.Status)
+			}
+		})
+	}
+}
+
+func TestDeleteReviewHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "author",
+			headers:  customerHeaders(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "staff user",
+			headers:  staffHeaders(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodDelete, "/api/v1/books/1/reviews/1", "", tc.headers)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
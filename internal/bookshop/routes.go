@@ -1,12 +1,122 @@
 package bookshop
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+)
 
 func (b *Bookshop) Routes() http.Handler {
 	mux := http.NewServeMux()
 
+	// Catalog changes, stock adjustments, refunds, review moderation and api
+	// key management are limited to staff, who must also use two-factor auth.
+	// The catalog is public, but api keys must hold the books:read scope.
+	booksRead := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requireAPIKeyScope(model.PermissionBooksRead, next)
+	}
+	booksWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requirePermission(model.PermissionBooksWrite, b.requireTwoFactor(next))
+	}
+	inventoryWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requirePermission(model.PermissionInventoryWrite, b.requireTwoFactor(next))
+	}
+	ordersWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requirePermission(model.PermissionOrdersWrite, b.requireTwoFactor(next))
+	}
+	reviewsModerate := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requirePermission(model.PermissionReviewsModerate, b.requireTwoFactor(next))
+	}
+	apiKeysWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		return b.requirePermission(model.PermissionAPIKeysWrite, b.requireTwoFactor(next))
+	}
+
 	mux.HandleFunc("GET /api/v1/health", b.healthHandler)
-	mux.HandleFunc("GET /api/v1/books", b.listBooksHandler)
+	mux.HandleFunc("GET /api/v1/books", booksRead(b.listBooksHandler))
+	mux.HandleFunc("POST /api/v1/books", booksWrite(b.createBookHandler))
+	mux.HandleFunc("GET /api/v1/books/{id}", booksRead(b.showBookHandler))
+	mux.HandleFunc("PATCH /api/v1/books/{id}", booksWrite(b.updateBookHandler))
+	mux.HandleFunc("DELETE /api/v1/books/{id}", booksWrite(b.deleteBookHandler))
+
+	mux.HandleFunc("GET /api/v1/books/{id}/inventory", inventoryWrite(b.showInventoryHandler))
+	mux.HandleFunc("POST /api/v1/books/{id}/inventory/adjustments", inventoryWrite(b.adjustInventoryHandler))
+	mux.HandleFunc("GET /api/v1/books/{id}/inventory/movements", inventoryWrite(b.listInventoryMovementsHandler))
+
+	mux.HandleFunc("GET /api/v1/books/{id}/reviews", booksRead(b.listReviewsHandler))
+	mux.HandleFunc("POST /api/v1/books/{id}/reviews", b.requireActivatedUser(b.createReviewHandler))
+	mux.HandleFunc("GET /api/v1/books/{id}/reviews/{review_id}", booksRead(b.showReviewHandler))
+	mux.HandleFunc("PATCH /api/v1/books/{id}/reviews/{review_id}", b.requireActivatedUser(b.updateReviewHandler))
+	mux.HandleFunc("DELETE /api/v1/books/{id}/reviews/{review_id}", b.requireActivatedUser(b.deleteReviewHandler))
+
+	mux.HandleFunc("GET /api/v1/cart", b.requireActivatedUser(b.showCartHandler))
+	mux.HandleFunc("POST /api/v1/cart/items", b.requireActivatedUser(b.addCartItemHandler))
+	mux.HandleFunc("PATCH /api/v1/cart/items/{book_id}", b.requireActivatedUser(b.updateCartItemHandler))
+	mux.HandleFunc("DELETE /api/v1/cart/items/{book_id}", b.requireActivatedUser(b.removeCartItemHandler))
+
+	mux.HandleFunc("POST /api/v1/orders", b.requireActivatedUser(b.createOrderHandler))
+	mux.HandleFunc("GET /api/v1/orders/{id}", b.requireActivatedUser(b.showOrderHandler))
+	mux.HandleFunc("POST /api/v1/orders/{id}/payment", b.requireActivatedUser(b.payOrderHandler))
+	mux.HandleFunc("POST /api/v1/orders/{id}/cancel", b.requireActivatedUser(b.cancelOrderHandler))
+	mux.HandleFunc("POST /api/v1/orders/{id}/refund", ordersWrite(b.refundOrderHandler))
+
+	mux.HandleFunc("POST /api/v1/payments/webhook", b.paymentWebhookHandler)
+
+	mux.HandleFunc("GET /api/v1/moderation/reviews", reviewsModerate(b.listModerationReviewsHandler))
+	mux.HandleFunc("POST /api/v1/moderation/reviews/{id}/approve", reviewsModerate(b.approveReviewHandler))
+	mux.HandleFunc("POST /api/v1/moderation/reviews/{id}/reject", reviewsModerate(b.rejectReviewHandler))
+
+	mux.HandleFunc("POST /api/v1/users", b.registerUserHandler)
+	mux.HandleFunc("PUT /api/v1/users/activated", b.activateUserHandler)
+	mux.HandleFunc("PUT /api/v1/users/password", b.updateUserPasswordHandler)
+	mux.HandleFunc("GET /api/v1/users/me", b.requireAuthenticatedUser(b.showCurrentUserHandler))
+	mux.HandleFunc("POST /api/v1/users/me/totp", b.requireActivatedUser(b.enrollTOTPHandler))
+	mux.HandleFunc("POST /api/v1/users/me/totp/verify", b.requireActivatedUser(b.verifyTOTPHandler))
+
+	mux.HandleFunc("POST /api/v1/tokens/authentication", b.createAuthenticationTokenHandler)
+	mux.HandleFunc("POST /api/v1/tokens/two-factor", b.createTwoFactorAuthenticationTokenHandler)
+	mux.HandleFunc("POST /api/v1/tokens/password-reset", b.createPasswordResetTokenHandler)
+
+	mux.HandleFunc("GET /api/v1/api-keys", apiKeysWrite(b.listAPIKeysHandler))
+	mux.HandleFunc("POST /api/v1/api-keys", apiKeysWrite(b.createAPIKeyHandler))
+	mux.HandleFunc("DELETE /api/v1/api-keys/{id}", apiKeysWrite(b.revokeAPIKeyHandler))
+
+	mux.Handle("/", b.unmatchedRouteHandler(mux))
+
+	return b.logRequest(b.recoverPanic(b.enableCORS(b.rateLimit(b.authenticate(mux)))))
+}
+
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// unmatchedRouteHandler is registered as the catch-all pattern of the mux. It
+// probes the mux with every supported method to tell apart a path that does
+// not exist from a path that exists but does not accept the request method.
+func (b *Bookshop) unmatchedRouteHandler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := make([]string, 0, len(routeMethods))
+
+		for _, method := range routeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+				allowed = append(allowed, method)
+			}
+		}
+
+		if len(allowed) < 1 {
+			b.notFound(w, r)
+			return
+		}
 
-	return mux
+		b.methodNotAllowed(w, r, strings.Join(allowed, ", "))
+	})
 }
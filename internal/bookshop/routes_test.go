@@ -0,0 +1,88 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestUnmatchedRoutes(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		wantCode    int
+		wantAllow   string
+		wantMessage string
+	}{
+		{
+			name:        "unknown path",
+			method:      http.MethodGet,
+			path:        "/api/v1/unknown",
+			wantCode:    http.StatusNotFound,
+			wantMessage: "The requested resource could not be found.",
+		},
+		{
+			name:        "method not allowed",
+			method:      http.MethodPut,
+			path:        "/api/v1/books",
+			wantCode:    http.StatusMethodNotAllowed,
+			wantAllow:   "GET, HEAD, POST",
+			wantMessage: "The PUT method is not supported for this resource.",
+		},
+		{
+			name:        "method not allowed with path value",
+			method:      http.MethodPost,
+			path:        "/api/v1/books/1",
+			wantCode:    http.StatusMethodNotAllowed,
+			wantAllow:   "GET, HEAD, PATCH, DELETE",
+			wantMessage: "The POST method is not supported for this resource.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, res.StatusCode)
+			}
+
+			allow := res.Header.Get("Allow")
+			if allow != tc.wantAllow {
+				t.Errorf("expected allow header to be %q; got %q", tc.wantAllow, allow)
+			}
+
+			var body struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			}
+
+			if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body.Message != tc.wantMessage {
+				t.Errorf("expected error message to be %s; got %s", tc.wantMessage, body.Message)
+			}
+		})
+	}
+}
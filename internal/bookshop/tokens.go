@@ -0,0 +1,281 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/totp"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const (
+	authenticationTokenTTL = time.Hour * 24
+	passwordResetTokenTTL  = time.Minute * 45
+	twoFactorTokenTTL      = time.Minute * 5
+
+	// twoFactorMaxAttempts is the number of wrong codes a two-factor token
+	// survives, which keeps guessing a code within its lifetime impractical.
+	twoFactorMaxAttempts = 5
+)
+
+type createAuthenticationTokenResponse struct {
+	Code                int          `json:"code"`
+	AuthenticationToken *model.Token `json:"authentication_token"`
+}
+
+func (b *Bookshop) createAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	model.ValidateEmail(v, input.Email)
+	model.ValidatePasswordPlaintext(v, input.Password)
+
+	if !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user, err := b.models.Users.GetByEmail(r.Context(), input.Email)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.invalidCredentials(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	match, err := user.Password.Matches(input.Password)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if !match {
+		b.invalidCredentials(w, r)
+		return
+	}
+
+	t, err := b.models.TOTP.Get(r.Context(), user.ID)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err == nil && t.Enabled {
+		b.twoFactorRequired(w, r, user)
+		return
+	}
+
+	b.issueAuthenticationToken(w, r, user, false)
+}
+
+func (b *Bookshop) issueAuthenticationToken(w http.ResponseWriter, r *http.Request, user *model.User, twoFactor bool) {
+	token, err := model.GenerateToken(user.ID, authenticationTokenTTL, model.ScopeAuthentication)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	token.TwoFactor = twoFactor
+
+	if err := b.models.Tokens.Insert(r.Context(), token); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := createAuthenticationTokenResponse{
+		Code:                http.StatusCreated,
+		AuthenticationToken: token,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type twoFactorRequiredResponse struct {
+	Code           int          `json:"code"`
+	Message        string       `json:"message"`
+	TwoFactorToken *model.Token `json:"two_factor_token"`
+}
+
+// twoFactorRequired issues a short-lived token that only proves the password
+// step succeeded. It must be exchanged, together with a TOTP or recovery code,
+// for a full authentication token.
+func (b *Bookshop) twoFactorRequired(w http.ResponseWriter, r *http.Request, user *model.User) {
+	token, err := b.models.Tokens.New(r.Context(), user.ID, twoFactorTokenTTL, model.ScopeTwoFactor)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := twoFactorRequiredResponse{
+		Code:           http.StatusAccepted,
+		Message:        "Two-factor verification required.",
+		TwoFactorToken: token,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+func (b *Bookshop) createTwoFactorAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token        string `json:"token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	model.ValidateTokenPlaintext(v, input.Token)
+
+	if input.Code == "" && input.RecoveryCode == "" {
+		v.AddError("code", "must be provided")
+	}
+
+	if !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user, err := b.models.Users.GetForToken(r.Context(), model.ScopeTwoFactor, input.Token)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			v.AddError("token", "invalid or expired two-factor token")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	t, err := b.models.TOTP.Get(r.Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			b.invalidCredentials(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	var verified bool
+
+	if input.Code != "" {
+		counter, ok := totp.Match(input.Code, t.Secret, time.Now())
+		if ok && t.Enabled {
+			verified, err = b.models.TOTP.UseCounter(r.Context(), user.ID, counter)
+		}
+	} else {
+		verified, err = b.models.TOTP.UseRecoveryCode(r.Context(), user.ID, input.RecoveryCode)
+	}
+
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if !verified {
+		err := b.models.Tokens.RecordFailedAttempt(r.Context(), model.ScopeTwoFactor, input.Token, twoFactorMaxAttempts)
+		if err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+		b.invalidCredentials(w, r)
+		return
+	}
+
+	if err := b.models.Tokens.DeleteAllForUser(r.Context(), model.ScopeTwoFactor, user.ID); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	b.issueAuthenticationToken(w, r, user, true)
+}
+
+type createPasswordResetTokenResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) createPasswordResetTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if model.ValidateEmail(v, input.Email); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	// The response is the same whether or not the email address belongs to an
+	// activated user, so that clients cannot use this endpoint to find out
+	// which email addresses are registered.
+	res := createPasswordResetTokenResponse{
+		Code:    http.StatusAccepted,
+		Message: "If the email address is registered, password reset instructions will be sent to it.",
+	}
+
+	user, err := b.models.Users.GetByEmail(r.Context(), input.Email)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err == nil && user.Activated {
+		// Issuing a new reset token revokes any previous one, so that only the
+		// most recently requested token can be used.
+		err := b.models.Tokens.DeleteAllForUser(r.Context(), model.ScopePasswordReset, user.ID)
+		if err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+
+		token, err := b.models.Tokens.New(r.Context(), user.ID, passwordResetTokenTTL, model.ScopePasswordReset)
+		if err != nil {
+			b.serverError(w, r, err)
+			return
+		}
+
+		b.sendEmail(user.Email, "password_reset.tmpl", map[string]any{
+			"passwordResetToken": token.Plaintext,
+		})
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
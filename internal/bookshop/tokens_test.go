@@ -0,0 +1,217 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
+	"github.com/dlbarduzzi/bookshop/internal/totp"
+)
+
+func TestCreateAuthenticationTokenHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		email    string
+		password string
+		wantCode int
+	}{
+		{
+			name:     "invalid email",
+			email:    "invalid",
+			password: mocks.UserPassword,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown email",
+			email:    "unknown@example.com",
+			password: mocks.UserPassword,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "wrong password",
+			email:    mocks.UserEmail,
+			password: "wrong-password",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "two-factor required",
+			email:    mocks.UserEmail,
+			password: mocks.UserPassword,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "success",
+			email:    mocks.CustomerEmail,
+			password: mocks.UserPassword,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			reqBody := `{"email": "` + tc.email + `", "password": "` + tc.password + `"}`
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/tokens/authentication", reqBody, nil)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode == http.StatusAccepted {
+				var res twoFactorRequiredResponse
+
+				if err := json.Unmarshal([]byte(body), &res); err != nil {
+					t.Fatal(err)
+				}
+
+				if res.TwoFactorToken == nil || len(res.TwoFactorToken.Plaintext) != 26 {
+					t.Errorf("expected two-factor token to be 26 bytes long; got %v", res.TwoFactorToken)
+				}
+				return
+			}
+
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+
+			var res createAuthenticationTokenResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.AuthenticationToken == nil || len(res.AuthenticationToken.Plaintext) != 26 {
+				t.Errorf("expected authentication token to be 26 bytes long; got %v", res.AuthenticationToken)
+			}
+		})
+	}
+}
+
+func TestCreatePasswordResetTokenHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{
+			name:     "invalid email",
+			email:    "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unregistered email",
+			email:    "unknown@example.com",
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "registered email",
+			email:    mocks.UserEmail,
+			wantCode: http.StatusAccepted,
+		},
+	}
+
+	wantMessage := "If the email address is registered, password reset instructions will be sent to it."
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			reqBody := `{"email": "` + tc.email + `"}`
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/tokens/password-reset", reqBody, nil)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusAccepted {
+				return
+			}
+
+			var res createPasswordResetTokenResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Message != wantMessage {
+				t.Errorf("expected response message to be %s; got %s", wantMessage, res.Message)
+			}
+		})
+	}
+}
+
+func TestCreateTwoFactorAuthenticationTokenHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	code, err := totp.Code(mocks.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "missing code",
+			body:     `{"token": "` + mocks.TwoFactorToken + `"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong scope token",
+			body:     `{"token": "` + mocks.AuthenticationToken + `", "code": "` + code + `"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid code",
+			body:     `{"token": "` + mocks.TwoFactorToken + `", "code": "000000x"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid recovery code",
+			body:     `{"token": "` + mocks.TwoFactorToken + `", "recovery_code": "zzzzz-zzzzz"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "valid code",
+			body:     `{"token": "` + mocks.TwoFactorToken + `", "code": "` + code + `"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "valid recovery code",
+			body:     `{"token": "` + mocks.TwoFactorToken + `", "recovery_code": "` + mocks.RecoveryCode + `"}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, _ := srv.request(t, http.MethodPost, "/api/v1/tokens/two-factor", tc.body, nil)
+			if code != tc.wantCode {
+				t.Errorf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+		})
+	}
+}
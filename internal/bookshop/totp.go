@@ -0,0 +1,143 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/totp"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const totpIssuer = "Bookshop"
+
+type enrollTOTPResponse struct {
+	Code   int    `json:"code"`
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+func (b *Bookshop) enrollTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	user := b.contextGetUser(r)
+	v := validator.NewValidator()
+
+	current, err := b.models.TOTP.Get(r.Context(), user.ID)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err == nil && current.Enabled {
+		v.AddError("totp", "two-factor authentication is already enabled")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	t := &model.TOTP{
+		UserID:  user.ID,
+		Secret:  secret,
+		Enabled: false,
+	}
+
+	if err := b.models.TOTP.Upsert(r.Context(), t); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := enrollTOTPResponse{
+		Code:   http.StatusCreated,
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Email, secret),
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type verifyTOTPResponse struct {
+	Code          int      `json:"code"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (b *Bookshop) verifyTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	user := b.contextGetUser(r)
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	t, err := b.models.TOTP.Get(r.Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			v.AddError("totp", "two-factor enrollment has not been started")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if t.Enabled {
+		v.AddError("totp", "two-factor authentication is already enabled")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	counter, ok := totp.Match(input.Code, t.Secret, time.Now())
+	if !ok {
+		v.AddError("code", "invalid two-factor code")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	// The code confirming the enrollment can't be used again to sign in.
+	used, err := b.models.TOTP.UseCounter(r.Context(), user.ID, counter)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if !used {
+		v.AddError("code", "invalid two-factor code")
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	recoveryCodes, err := model.GenerateRecoveryCodes()
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err := b.models.TOTP.Enable(r.Context(), user.ID, recoveryCodes); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := verifyTOTPResponse{
+		Code:          http.StatusOK,
+		RecoveryCodes: recoveryCodes,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
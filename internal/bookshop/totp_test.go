@@ -0,0 +1,137 @@
+package bookshop
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model/mocks"
+	"github.com/dlbarduzzi/bookshop/internal/totp"
+)
+
+func customerHeaders() http.Header {
+	headers := make(http.Header)
+	headers.Set("Authorization", "Bearer "+mocks.CustomerAuthenticationToken)
+	return headers
+}
+
+func TestEnrollTOTPHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		wantCode int
+	}{
+		{
+			name:     "anonymous user",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "already enabled",
+			headers:  staffHeaders(),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  customerHeaders(),
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/users/me/totp", "", tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+
+			var res enrollTOTPResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.URI != totp.URI(totpIssuer, mocks.CustomerEmail, res.Secret) {
+				t.Errorf("expected uri to match secret; got %s", res.URI)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPHandler(t *testing.T) {
+	t.Parallel()
+	app := newTestBookshop(t)
+
+	code, err := totp.Code(mocks.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		code     string
+		wantCode int
+	}{
+		{
+			name:     "already enabled",
+			headers:  staffHeaders(),
+			code:     code,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid code",
+			headers:  customerHeaders(),
+			code:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "success",
+			headers:  customerHeaders(),
+			code:     code,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, app.Routes())
+			defer srv.Close()
+
+			reqBody := `{"code": "` + tc.code + `"}`
+
+			code, body := srv.request(t, http.MethodPost, "/api/v1/users/me/totp/verify", reqBody, tc.headers)
+			if code != tc.wantCode {
+				t.Fatalf("expected status code to be %d; got %d", tc.wantCode, code)
+			}
+
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res verifyTOTPResponse
+
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.RecoveryCodes) != 10 {
+				t.Errorf("expected %d recovery codes; got %d", 10, len(res.RecoveryCodes))
+			}
+		})
+	}
+}
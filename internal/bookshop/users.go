@@ -0,0 +1,232 @@
+package bookshop
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
+	"github.com/dlbarduzzi/bookshop/internal/jsontil"
+	"github.com/dlbarduzzi/bookshop/internal/validator"
+)
+
+const activationTokenTTL = time.Hour * 24 * 3
+
+type registerUserResponse struct {
+	Code int         `json:"code"`
+	User *model.User `json:"user"`
+}
+
+func (b *Bookshop) registerUserHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	// Passwords must be validated before hashing, since bcrypt refuses to hash
+	// passwords longer than 72 bytes.
+	if model.ValidatePasswordPlaintext(v, input.Password); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user := &model.User{
+		Name:      input.Name,
+		Email:     input.Email,
+		Activated: false,
+	}
+
+	if err := user.Password.Set(input.Password); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if model.ValidateUser(v, user); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	token, err := model.GenerateToken(0, activationTokenTTL, model.ScopeActivation)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err := b.models.Users.Register(r.Context(), user, token, model.PermissionBooksRead); err != nil {
+		if errors.Is(err, model.ErrDuplicateEmail) {
+			v.AddError("email", "a user with this email address already exists")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	b.sendEmail(user.Email, "user_welcome.tmpl", map[string]any{
+		"name":            user.Name,
+		"activationToken": token.Plaintext,
+	})
+
+	res := registerUserResponse{
+		Code: http.StatusCreated,
+		User: user,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type activateUserResponse struct {
+	Code int         `json:"code"`
+	User *model.User `json:"user"`
+}
+
+func (b *Bookshop) activateUserHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	if model.ValidateTokenPlaintext(v, input.Token); !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user, err := b.models.Users.GetForToken(r.Context(), model.ScopeActivation, input.Token)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			v.AddError("token", "invalid or expired activation token")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	user.Activated = true
+
+	if err := b.models.Users.Update(r.Context(), user); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	err = b.models.Tokens.DeleteAllForUser(r.Context(), model.ScopeActivation, user.ID)
+	if err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := activateUserResponse{
+		Code: http.StatusOK,
+		User: user,
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type showCurrentUserResponse struct {
+	Code int         `json:"code"`
+	User *model.User `json:"user"`
+}
+
+func (b *Bookshop) showCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	res := showCurrentUserResponse{
+		Code: http.StatusOK,
+		User: b.contextGetUser(r),
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
+
+type updateUserPasswordResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *Bookshop) updateUserPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password string `json:"password"`
+		Token    string `json:"token"`
+	}
+
+	v := validator.NewValidator()
+
+	if err := jsontil.Unmarshal(w, r, &input); err != nil {
+		v.AddError("body", err.Error())
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	model.ValidatePasswordPlaintext(v, input.Password)
+	model.ValidateTokenPlaintext(v, input.Token)
+
+	if !v.IsValid() {
+		b.validationError(w, r, v.Errors)
+		return
+	}
+
+	user, err := b.models.Users.GetForToken(r.Context(), model.ScopePasswordReset, input.Token)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			v.AddError("token", "invalid or expired password reset token")
+			b.validationError(w, r, v.Errors)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	if err := user.Password.Set(input.Password); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+
+	// Reset tokens are single-use, and every existing session is revoked so
+	// that whoever knew the old password is signed out.
+	if err := b.models.Users.ResetPassword(r.Context(), user); err != nil {
+		if errors.Is(err, model.ErrEditConflict) {
+			b.editConflict(w, r)
+			return
+		}
+		b.serverError(w, r, err)
+		return
+	}
+
+	res := updateUserPasswordResponse{
+		Code:    http.StatusOK,
+		Message: "Your password was successfully reset.",
+	}
+
+	if err := jsontil.Marshal(w, res, res.Code, nil); err != nil {
+		b.serverError(w, r, err)
+		return
+	}
+}
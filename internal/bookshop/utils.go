@@ -1,13 +1,28 @@
 package bookshop
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/dlbarduzzi/bookshop/internal/bookshop/model"
 	"github.com/dlbarduzzi/bookshop/internal/validator"
 )
 
+func (b *Bookshop) readIDParam(r *http.Request) (int64, error) {
+	return b.readInt64Param(r, "id")
+}
+
+func (b *Bookshop) readInt64Param(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid %s parameter", name)
+	}
+	return id, nil
+}
+
 func (b *Bookshop) readString(q url.Values, k string, defaultValue string) string {
 	s := q.Get(k)
 	if s == "" {
@@ -36,3 +51,45 @@ func (b *Bookshop) readInt(q url.Values, k string, defaultValue int, v *validato
 	}
 	return i
 }
+
+func (b *Bookshop) readFloat(q url.Values, k string, defaultValue float64, v *validator.Validator) float64 {
+	s := q.Get(k)
+	if s == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		v.AddError(k, "must be a number")
+		return defaultValue
+	}
+	return f
+}
+
+// readBool returns nil when the parameter is missing, so callers can tell it
+// apart from an explicit false.
+func (b *Bookshop) readBool(q url.Values, k string, v *validator.Validator) *bool {
+	s := q.Get(k)
+	if s == "" {
+		return nil
+	}
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(k, "must be a boolean value")
+		return nil
+	}
+	return &value
+}
+
+// readPrice parses a decimal price in the given currency and returns it in the
+// minor unit of the currency, or nil when the price is empty.
+func (b *Bookshop) readPrice(s string, currency string, k string, v *validator.Validator) *int64 {
+	if s == "" {
+		return nil
+	}
+	price, err := model.ParseMoney(s, currency)
+	if err != nil {
+		v.AddError(k, "must be a decimal amount in a supported currency")
+		return nil
+	}
+	return &price.Amount
+}
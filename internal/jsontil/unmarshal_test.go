@@ -0,0 +1,86 @@
+package jsontil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "empty body",
+			body:    "",
+			wantErr: "body must not be empty",
+		},
+		{
+			name:    "syntax error",
+			body:    `{"foo": bar}`,
+			wantErr: "body contains badly-formed JSON (at character 9)",
+		},
+		{
+			name:    "unexpected eof",
+			body:    `{"foo": "bar"`,
+			wantErr: "body contains badly-formed JSON",
+		},
+		{
+			name:    "incorrect type",
+			body:    `{"foo": 1}`,
+			wantErr: `body contains incorrect JSON type for field "foo"`,
+		},
+		{
+			name:    "unknown field",
+			body:    `{"bar": "foo"}`,
+			wantErr: `body contains unknown key "bar"`,
+		},
+		{
+			name:    "trailing data",
+			body:    `{"foo": "bar"}{}`,
+			wantErr: "body must only contain a single JSON value",
+		},
+		{
+			name:    "too large",
+			body:    `{"foo": "` + strings.Repeat("a", maxBodyBytes) + `"}`,
+			wantErr: "body must not be larger than 1048576 bytes",
+		},
+		{
+			name: "success",
+			body: `{"foo": "bar"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var dst struct {
+				Foo string `json:"foo"`
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+
+			err := Unmarshal(w, r, &dst)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected error to be nil; got %v", err)
+				}
+				if dst.Foo != "bar" {
+					t.Errorf("expected foo to be %s; got %s", "bar", dst.Foo)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("expected error to be %s; got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed "templates"
+var templateFS embed.FS
+
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Sender   string
+	Timeout  time.Duration
+}
+
+type Mailer struct {
+	config *Config
+}
+
+func NewMailer(config *Config) (*Mailer, error) {
+	cfg, err := config.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Mailer{config: cfg}, nil
+}
+
+func (c *Config) parse() (*Config, error) {
+	if c.Host == "" {
+		return nil, fmt.Errorf("invalid mailer host")
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		return nil, fmt.Errorf("invalid mailer '%d' port number", c.Port)
+	}
+
+	if _, err := mail.ParseAddress(c.Sender); err != nil {
+		return nil, fmt.Errorf("invalid mailer '%s' sender address", c.Sender)
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second * 10
+	}
+
+	return c, nil
+}
+
+type message struct {
+	subject   string
+	plainBody string
+	htmlBody  string
+}
+
+func render(templateFile string, data any) (*message, error) {
+	textTmpl, err := template.New("").ParseFS(templateFS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlTmpl, err := htmltemplate.New("").ParseFS(templateFS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := textTmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	if err := textTmpl.ExecuteTemplate(plainBody, "plainBody", data); err != nil {
+		return nil, err
+	}
+
+	htmlBody := new(bytes.Buffer)
+	if err := htmlTmpl.ExecuteTemplate(htmlBody, "htmlBody", data); err != nil {
+		return nil, err
+	}
+
+	return &message{
+		subject:   strings.TrimSpace(subject.String()),
+		plainBody: strings.TrimSpace(plainBody.String()),
+		htmlBody:  strings.TrimSpace(htmlBody.String()),
+	}, nil
+}
+
+func (m *Mailer) Send(recipient string, templateFile string, data any) error {
+	msg, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	body, err := m.compose(recipient, msg)
+	if err != nil {
+		return err
+	}
+
+	return m.deliver(recipient, body)
+}
+
+func (m *Mailer) compose(recipient string, msg *message) ([]byte, error) {
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "From: %s\r\n", m.config.Sender)
+	fmt.Fprintf(buf, "To: %s\r\n", recipient)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%q\r\n", boundary)
+	fmt.Fprintf(buf, "\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain", body: msg.plainBody},
+		{contentType: "text/html", body: msg.htmlBody},
+	}
+
+	for _, part := range parts {
+		fmt.Fprintf(buf, "--%s\r\n", boundary)
+		fmt.Fprintf(buf, "Content-Type: %s; charset=UTF-8\r\n", part.contentType)
+		fmt.Fprintf(buf, "Content-Transfer-Encoding: quoted-printable\r\n")
+		fmt.Fprintf(buf, "\r\n")
+
+		qp := quotedprintable.NewWriter(buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(buf, "\r\n")
+	}
+
+	fmt.Fprintf(buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func (m *Mailer) deliver(recipient string, body []byte) error {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	conn, err := net.DialTimeout("tcp", addr, m.config.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(m.config.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	sender, err := mail.ParseAddress(m.config.Sender)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Mail(sender.Address); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(recipient); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
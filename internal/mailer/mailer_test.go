@@ -0,0 +1,175 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConfigParse(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{}
+
+	_, err := config.parse()
+	wantErr := "invalid mailer host"
+
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("expected error to be %v; got %v", wantErr, err)
+	}
+
+	config = &Config{Host: "localhost", Port: 1025, Sender: "invalid"}
+
+	_, err = config.parse()
+	wantErr = "invalid mailer 'invalid' sender address"
+
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("expected error to be %v; got %v", wantErr, err)
+	}
+
+	config.Sender = "Bookshop <no-reply@bookshop.com>"
+
+	if _, err := config.parse(); err != nil {
+		t.Fatalf("expected error to be nil; got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{"name": "Test", "activationToken": "TESTTOKEN"}
+
+	msg, err := render("user_welcome.tmpl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSubject := "Welcome to Bookshop!"
+	if msg.subject != wantSubject {
+		t.Errorf("expected subject to be %s; got %s", wantSubject, msg.subject)
+	}
+
+	if !strings.Contains(msg.plainBody, "TESTTOKEN") {
+		t.Error("expected plain body to contain activation token")
+	}
+
+	if !strings.Contains(msg.htmlBody, "TESTTOKEN") {
+		t.Error("expected html body to contain activation token")
+	}
+
+	if _, err := render("not_found.tmpl", data); err == nil {
+		t.Error("expected error not to be nil")
+	}
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	sink, received := newTestSMTPSink(t)
+	defer sink.Close()
+
+	host, port, err := net.SplitHostPort(sink.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMailer(&Config{
+		Host:   host,
+		Port:   portNumber,
+		Sender: "Bookshop <no-reply@bookshop.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"passwordResetToken": "TESTTOKEN"}
+
+	if err := m.Send("test@example.com", "password_reset.tmpl", data); err != nil {
+		t.Fatalf("expected error to be nil; got %v", err)
+	}
+
+	msg := <-received
+
+	if !strings.Contains(msg, "To: test@example.com") {
+		t.Error("expected message to contain recipient header")
+	}
+
+	if !strings.Contains(msg, "Subject: Reset your Bookshop password") {
+		t.Error("expected message to contain subject header")
+	}
+
+	if !strings.Contains(msg, "TESTTOKEN") {
+		t.Error("expected message to contain password reset token")
+	}
+}
+
+// newTestSMTPSink starts a minimal SMTP server that accepts a single message
+// and sends its data over the returned channel.
+func newTestSMTPSink(t *testing.T) (net.Listener, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end data with <CR><LF>.<CR><LF>")
+
+				var data strings.Builder
+
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+
+				received <- data.String()
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return ln, received
+}
@@ -0,0 +1,180 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FakeProviderName = "fake"
+
+	// FakeSignatureHeader holds the signature of the webhooks of the fake
+	// provider, in the "t=<unix time>,v1=<hex hmac>" format.
+	FakeSignatureHeader = "Fake-Signature"
+
+	fakeSignatureTolerance = time.Minute * 5
+)
+
+// Tokens understood by the fake provider. Any other token is declined as
+// invalid.
+const (
+	FakeTokenApproved     = "tok_approved"
+	FakeTokenDeclined     = "tok_declined"
+	FakeTokenCaptureFails = "tok_capture_fails"
+)
+
+// FakeProvider is an in-process provider that keeps payments in memory. The
+// outcome of a payment is decided by the token it's authorized with.
+type FakeProvider struct {
+	secret string
+
+	mu       sync.Mutex
+	sequence int
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	Payment
+	token string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   webhookSecret,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sequence++
+
+	payment := &fakePayment{
+		Payment: Payment{
+			ID:       fmt.Sprintf("fake_pay_%d", p.sequence),
+			OrderID:  req.OrderID,
+			Status:   StatusAuthorized,
+			Amount:   req.Amount,
+			Currency: req.Currency,
+		},
+		token: req.Token,
+	}
+
+	switch req.Token {
+	case FakeTokenApproved, FakeTokenCaptureFails:
+	case FakeTokenDeclined:
+		payment.Status = StatusFailed
+		payment.FailureReason = "card_declined"
+	default:
+		payment.Status = StatusFailed
+		payment.FailureReason = "invalid_token"
+	}
+
+	p.payments[payment.ID] = payment
+
+	result := payment.Payment
+	return &result, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, paymentID string) (*Payment, error) {
+	return p.transition(paymentID, StatusAuthorized, func(payment *fakePayment) {
+		if payment.token == FakeTokenCaptureFails {
+			payment.Status = StatusFailed
+			payment.FailureReason = "capture_failed"
+			return
+		}
+		payment.Status = StatusCaptured
+	})
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, paymentID string) (*Payment, error) {
+	return p.transition(paymentID, StatusCaptured, func(payment *fakePayment) {
+		payment.Status = StatusRefunded
+	})
+}
+
+func (p *FakeProvider) transition(paymentID string, from Status, fn func(*fakePayment)) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+
+	if payment.Status != from {
+		return nil, ErrInvalidPaymentState
+	}
+
+	fn(payment)
+
+	result := payment.Payment
+	return &result, nil
+}
+
+// SignWebhook returns the signature header value of a webhook payload sent at
+// the given time, which is how the fake provider signs its callbacks.
+func (p *FakeProvider) SignWebhook(payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, p.signature(timestamp, payload))
+}
+
+func (p *FakeProvider) signature(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature of a webhook payload and returns the event
+// it holds. Signatures older than a few minutes are rejected to limit replays.
+func (p *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	var timestamp, signature string
+
+	for _, part := range strings.Split(header.Get(FakeSignatureHeader), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	if age := time.Since(time.Unix(unix, 0)); age > fakeSignatureTolerance || age < -fakeSignatureTolerance {
+		return nil, ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(p.signature(timestamp, payload))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,171 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFakeProviderPayments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		token         string
+		wantAuthorize Status
+		wantCapture   Status
+		wantReason    string
+	}{
+		{
+			name:          "approved",
+			token:         FakeTokenApproved,
+			wantAuthorize: StatusAuthorized,
+			wantCapture:   StatusCaptured,
+		},
+		{
+			name:          "declined",
+			token:         FakeTokenDeclined,
+			wantAuthorize: StatusFailed,
+			wantReason:    "card_declined",
+		},
+		{
+			name:          "invalid token",
+			token:         "tok_unknown",
+			wantAuthorize: StatusFailed,
+			wantReason:    "invalid_token",
+		},
+		{
+			name:          "capture fails",
+			token:         FakeTokenCaptureFails,
+			wantAuthorize: StatusAuthorized,
+			wantCapture:   StatusFailed,
+			wantReason:    "capture_failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			provider := NewFakeProvider("secret")
+
+			payment, err := provider.Authorize(ctx, AuthorizeRequest{OrderID: 1, Amount: 1000, Currency: "USD", Token: tc.token})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if payment.Status != tc.wantAuthorize {
+				t.Fatalf("expected authorized status to be %s; got %s", tc.wantAuthorize, payment.Status)
+			}
+
+			if tc.wantCapture == "" {
+				if _, err := provider.Capture(ctx, payment.ID); !errors.Is(err, ErrInvalidPaymentState) {
+					t.Fatalf("expected error to be %v; got %v", ErrInvalidPaymentState, err)
+				}
+			} else {
+				if payment, err = provider.Capture(ctx, payment.ID); err != nil {
+					t.Fatal(err)
+				}
+
+				if payment.Status != tc.wantCapture {
+					t.Fatalf("expected captured status to be %s; got %s", tc.wantCapture, payment.Status)
+				}
+			}
+
+			if payment.FailureReason != tc.wantReason {
+				t.Errorf("expected failure reason to be %q; got %q", tc.wantReason, payment.FailureReason)
+			}
+		})
+	}
+}
+
+func TestFakeProviderRefund(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	provider := NewFakeProvider("secret")
+
+	if _, err := provider.Refund(ctx, "fake_pay_1"); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected error to be %v; got %v", ErrPaymentNotFound, err)
+	}
+
+	payment, err := provider.Authorize(ctx, AuthorizeRequest{OrderID: 1, Amount: 1000, Currency: "USD", Token: FakeTokenApproved})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Refund(ctx, payment.ID); !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("expected error to be %v; got %v", ErrInvalidPaymentState, err)
+	}
+
+	if _, err := provider.Capture(ctx, payment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	payment, err = provider.Refund(ctx, payment.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payment.Status != StatusRefunded {
+		t.Errorf("expected status to be %s; got %s", StatusRefunded, payment.Status)
+	}
+}
+
+func TestFakeProviderVerifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFakeProvider("secret")
+	payload := []byte(`{"id": "evt_1", "type": "payment.captured", "payment_id": "fake_pay_1"}`)
+
+	testCases := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{
+			name:      "missing signature",
+			signature: "",
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "wrong secret",
+			signature: NewFakeProvider("other").SignWebhook(payload, time.Now()),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "expired",
+			signature: provider.SignWebhook(payload, time.Now().Add(-time.Hour)),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "valid",
+			signature: provider.SignWebhook(payload, time.Now()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := make(http.Header)
+			header.Set(FakeSignatureHeader, tc.signature)
+
+			event, err := provider.VerifyWebhook(payload, header)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error to be %v; got %v", tc.wantErr, err)
+			}
+
+			if tc.wantErr != nil {
+				return
+			}
+
+			if event.Type != EventPaymentCaptured || event.PaymentID != "fake_pay_1" {
+				t.Errorf("expected captured event of fake_pay_1; got %+v", event)
+			}
+		})
+	}
+}
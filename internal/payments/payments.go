@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type Status string
+
+const (
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusFailed     Status = "failed"
+	StatusRefunded   Status = "refunded"
+)
+
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed   = "payment.failed"
+	EventPaymentRefunded = "payment.refunded"
+)
+
+var (
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrInvalidPaymentState = errors.New("invalid payment state")
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+)
+
+// AuthorizeRequest asks a provider to hold Amount, in the minor unit of
+// Currency, on the payment method identified by Token.
+type AuthorizeRequest struct {
+	OrderID  int64
+	Amount   int64
+	Currency string
+	Token    string
+}
+
+// Payment is the state of a payment at the provider. A declined payment is not
+// an error, it's reported with the failed status and a FailureReason.
+type Payment struct {
+	ID            string
+	OrderID       int64
+	Status        Status
+	Amount        int64
+	Currency      string
+	FailureReason string
+}
+
+// Event is a webhook callback sent by a provider when a payment changes.
+type Event struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	PaymentID string `json:"payment_id"`
+}
+
+// Provider is implemented by every payment gateway the bookshop can take
+// payments with.
+type Provider interface {
+	Name() string
+	Authorize(context.Context, AuthorizeRequest) (*Payment, error)
+	Capture(context.Context, string) (*Payment, error)
+	Refund(context.Context, string) (*Payment, error)
+	VerifyWebhook([]byte, http.Header) (*Event, error)
+}
+
+type Config struct {
+	Provider      string
+	WebhookSecret string
+}
+
+// NewProvider returns the provider named in the config.
+func NewProvider(config *Config) (Provider, error) {
+	switch config.Provider {
+	case FakeProviderName:
+		if config.WebhookSecret == "" {
+			return nil, fmt.Errorf("invalid payments webhook secret")
+		}
+		return NewFakeProvider(config.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("invalid payments '%s' provider", config.Provider)
+	}
+}
@@ -0,0 +1,30 @@
+package payments
+
+import "testing"
+
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProvider(&Config{Provider: "unknown"})
+	wantErr := "invalid payments 'unknown' provider"
+
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("expected error to be %v; got %v", wantErr, err)
+	}
+
+	_, err = NewProvider(&Config{Provider: FakeProviderName})
+	wantErr = "invalid payments webhook secret"
+
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("expected error to be %v; got %v", wantErr, err)
+	}
+
+	provider, err := NewProvider(&Config{Provider: FakeProviderName, WebhookSecret: "secret"})
+	if err != nil {
+		t.Fatalf("expected error to be nil; got %v", err)
+	}
+
+	if provider.Name() != FakeProviderName {
+		t.Errorf("expected provider name to be %s; got %s", FakeProviderName, provider.Name())
+	}
+}
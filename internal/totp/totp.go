@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	digits = 6
+	period = 30
+	// skew is the number of periods before and after the current one that are
+	// still accepted, to tolerate clock drift between server and device.
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+func URI(issuer string, account string, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprintf("%d", digits))
+	q.Set("period", fmt.Sprintf("%d", period))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, q.Encode())
+}
+
+func Code(secret string, t time.Time) (string, error) {
+	return code(secret, uint64(t.Unix())/period)
+}
+
+func Validate(passcode string, secret string, t time.Time) bool {
+	_, ok := Match(passcode, secret, t)
+	return ok
+}
+
+// Match validates a passcode like Validate and also returns the counter of the
+// period it belongs to, so callers can refuse a code that was already used.
+func Match(passcode string, secret string, t time.Time) (int64, bool) {
+	if len(passcode) != digits {
+		return 0, false
+	}
+
+	counter := int64(t.Unix()) / period
+
+	for i := int64(-skew); i <= skew; i++ {
+		if counter+i < 0 {
+			continue
+		}
+		want, err := code(secret, uint64(counter+i))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(passcode)) == 1 {
+			return counter + i, true
+		}
+	}
+
+	return 0, false
+}
+
+func code(secret string, counter uint64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1_000_000), nil
+}
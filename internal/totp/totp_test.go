@@ -0,0 +1,103 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the base32 encoding of the "12345678901234567890" key used by
+// the RFC 6238 test vectors.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		unix     int64
+		wantCode string
+	}{
+		{unix: 59, wantCode: "287082"},
+		{unix: 1111111109, wantCode: "081804"},
+		{unix: 1234567890, wantCode: "005924"},
+		{unix: 2000000000, wantCode: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := Code(rfcSecret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.wantCode {
+			t.Errorf("expected code at %d to be %s; got %s", tc.unix, tc.wantCode, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1111111109, 0)
+
+	if !Validate("081804", rfcSecret, now) {
+		t.Error("expected current code to be valid")
+	}
+
+	if !Validate("081804", rfcSecret, now.Add(time.Second*30)) {
+		t.Error("expected previous period code to be valid")
+	}
+
+	if Validate("081804", rfcSecret, now.Add(time.Minute*5)) {
+		t.Error("expected expired code to be invalid")
+	}
+
+	if Validate("12345", rfcSecret, now) {
+		t.Error("expected short code to be invalid")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1111111109, 0)
+
+	counter, ok := Match("081804", rfcSecret, now.Add(time.Second*30))
+	if !ok {
+		t.Fatal("expected previous period code to match")
+	}
+
+	if want := int64(1111111109 / 30); counter != want {
+		t.Errorf("expected counter to be %d; got %d", want, counter)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	t.Parallel()
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret) != 32 {
+		t.Errorf("expected secret to be %d bytes long; got %d", 32, len(secret))
+	}
+
+	if _, err := Code(secret, time.Now()); err != nil {
+		t.Errorf("expected generated secret to be valid; got %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+
+	uri := URI("Bookshop", "test@example.com", rfcSecret)
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Bookshop:test@example.com?") {
+		t.Errorf("expected uri to have otpauth prefix; got %s", uri)
+	}
+
+	if !strings.Contains(uri, "secret="+rfcSecret) {
+		t.Errorf("expected uri to contain secret; got %s", uri)
+	}
+}
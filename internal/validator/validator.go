@@ -1,6 +1,10 @@
 package validator
 
-import "slices"
+import (
+	"cmp"
+	"regexp"
+	"slices"
+)
 
 type Errors map[string]string
 
@@ -25,3 +29,26 @@ func (v *Validator) AddError(key string, value string) {
 func ValueInList[T comparable](value T, list ...T) bool {
 	return slices.Contains(list, value)
 }
+
+func ValueInRange[T cmp.Ordered](value T, minValue T, maxValue T) bool {
+	return value >= minValue && value <= maxValue
+}
+
+func Unique[T comparable](values []T) bool {
+	seen := make(map[T]struct{}, len(values))
+	for _, value := range values {
+		if _, exists := seen[value]; exists {
+			return false
+		}
+		seen[value] = struct{}{}
+	}
+	return true
+}
+
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}
+
+var EmailRX = regexp.MustCompile(
+	"^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$",
+)
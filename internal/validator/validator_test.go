@@ -1,6 +1,9 @@
 package validator
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestIsValid(t *testing.T) {
 	t.Parallel()
@@ -12,3 +15,41 @@ func TestIsValid(t *testing.T) {
 		t.Error("expected validator to be false")
 	}
 }
+
+func TestValueInRange(t *testing.T) {
+	t.Parallel()
+
+	if !ValueInRange(5, 1, 10) {
+		t.Error("expected 5 to be in range 1..10")
+	}
+
+	if ValueInRange(11, 1, 10) {
+		t.Error("expected 11 not to be in range 1..10")
+	}
+}
+
+func TestUnique(t *testing.T) {
+	t.Parallel()
+
+	if !Unique([]string{"foo", "bar"}) {
+		t.Error("expected values to be unique")
+	}
+
+	if Unique([]string{"foo", "bar", "foo"}) {
+		t.Error("expected values not to be unique")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	t.Parallel()
+
+	rx := regexp.MustCompile(`^\d+$`)
+
+	if !Matches("123", rx) {
+		t.Error("expected value to match")
+	}
+
+	if Matches("abc", rx) {
+		t.Error("expected value not to match")
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS users_totp (
+  user_id bigint PRIMARY KEY REFERENCES users ON DELETE CASCADE,
+  secret text NOT NULL,
+  enabled bool NOT NULL DEFAULT false,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+  hash bytea PRIMARY KEY,
+  user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE
+);
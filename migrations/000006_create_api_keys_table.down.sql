@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE code = 'api-keys:write';
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+  id bigserial PRIMARY KEY,
+  name text NOT NULL,
+  hash bytea UNIQUE NOT NULL,
+  prefix text NOT NULL,
+  scopes text[] NOT NULL,
+  rps double precision NOT NULL,
+  burst integer NOT NULL,
+  created_by bigint REFERENCES users ON DELETE SET NULL,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  last_used_at timestamp(0) with time zone,
+  revoked_at timestamp(0) with time zone
+);
+
+INSERT INTO permissions (code) VALUES ('api-keys:write');
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS reviews (
+  id bigserial PRIMARY KEY,
+  book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+  user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+  rating smallint NOT NULL,
+  body text NOT NULL,
+  version integer NOT NULL DEFAULT 1,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  CONSTRAINT reviews_user_id_book_id_key UNIQUE (user_id, book_id),
+  CONSTRAINT reviews_rating_check CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_book_id_idx ON reviews (book_id);
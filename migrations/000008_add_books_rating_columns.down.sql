@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS reviews_refresh_book_rating ON reviews;
+DROP FUNCTION IF EXISTS reviews_refresh_book_rating();
+DROP FUNCTION IF EXISTS refresh_book_rating(bigint);
+DROP INDEX IF EXISTS books_average_rating_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS review_count;
+ALTER TABLE books DROP COLUMN IF EXISTS average_rating;
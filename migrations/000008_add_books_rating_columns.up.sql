@@ -0,0 +1,41 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS average_rating numeric(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS review_count integer NOT NULL DEFAULT 0;
+
+UPDATE books
+SET average_rating = ratings.average_rating, review_count = ratings.review_count
+FROM (
+  SELECT book_id, ROUND(AVG(rating), 2) AS average_rating, count(*) AS review_count
+  FROM reviews
+  GROUP BY book_id
+) ratings
+WHERE books.id = ratings.book_id;
+
+CREATE INDEX IF NOT EXISTS books_average_rating_idx ON books (average_rating, id);
+
+-- Keep the denormalised rating of a book in sync with its reviews, so listing
+-- and sorting books by rating doesn't need to aggregate the reviews table.
+CREATE OR REPLACE FUNCTION refresh_book_rating(target_book_id bigint) RETURNS void AS $$
+  UPDATE books
+  SET (average_rating, review_count) = (
+    SELECT COALESCE(ROUND(AVG(rating), 2), 0), count(*)
+    FROM reviews
+    WHERE book_id = target_book_id
+  )
+  WHERE id = target_book_id;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION reviews_refresh_book_rating() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    PERFORM refresh_book_rating(OLD.book_id);
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    PERFORM refresh_book_rating(NEW.book_id);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_refresh_book_rating
+AFTER INSERT OR UPDATE OR DELETE ON reviews
+FOR EACH ROW EXECUTE FUNCTION reviews_refresh_book_rating();
@@ -0,0 +1,19 @@
+DELETE FROM permissions WHERE code = 'reviews:moderate';
+
+CREATE OR REPLACE FUNCTION refresh_book_rating(target_book_id bigint) RETURNS void AS $$
+  UPDATE books
+  SET (average_rating, review_count) = (
+    SELECT COALESCE(ROUND(AVG(rating), 2), 0), count(*)
+    FROM reviews
+    WHERE book_id = target_book_id
+  )
+  WHERE id = target_book_id;
+$$ LANGUAGE sql;
+
+DROP INDEX IF EXISTS reviews_status_created_at_idx;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_status_check;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_by;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderation_reason;
+ALTER TABLE reviews DROP COLUMN IF EXISTS flagged;
+ALTER TABLE reviews DROP COLUMN IF EXISTS status;
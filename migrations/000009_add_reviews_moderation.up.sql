@@ -0,0 +1,26 @@
+-- Reviews written before moderation existed were already public, so they are
+-- approved here, while new reviews start as pending.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'approved';
+ALTER TABLE reviews ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS flagged boolean NOT NULL DEFAULT false;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderation_reason text NOT NULL DEFAULT '';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_by bigint REFERENCES users ON DELETE SET NULL;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at timestamp(0) with time zone;
+
+ALTER TABLE reviews ADD CONSTRAINT reviews_status_check
+  CHECK (status IN ('pending', 'approved', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS reviews_status_created_at_idx ON reviews (status, created_at);
+
+-- Only approved reviews count towards the rating of a book.
+CREATE OR REPLACE FUNCTION refresh_book_rating(target_book_id bigint) RETURNS void AS $$
+  UPDATE books
+  SET (average_rating, review_count) = (
+    SELECT COALESCE(ROUND(AVG(rating), 2), 0), count(*)
+    FROM reviews
+    WHERE book_id = target_book_id AND status = 'approved'
+  )
+  WHERE id = target_book_id;
+$$ LANGUAGE sql;
+
+INSERT INTO permissions (code) VALUES ('reviews:moderate');
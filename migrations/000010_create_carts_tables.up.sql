@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS carts (
+  id bigserial PRIMARY KEY,
+  user_id bigint UNIQUE NOT NULL REFERENCES users ON DELETE CASCADE,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- The unit price of a cart item is a snapshot of the book price, in the minor
+-- unit of its currency, taken when the book is first added to the cart. Books
+-- have no price yet, so items are snapshotted as free.
+CREATE TABLE IF NOT EXISTS cart_items (
+  cart_id bigint NOT NULL REFERENCES carts ON DELETE CASCADE,
+  book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+  quantity integer NOT NULL,
+  unit_price bigint NOT NULL DEFAULT 0,
+  currency char(3) NOT NULL DEFAULT 'USD',
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (cart_id, book_id),
+  CONSTRAINT cart_items_quantity_check CHECK (quantity BETWEEN 1 AND 100)
+);
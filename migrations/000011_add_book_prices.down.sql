@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS books_price_idx;
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_price_check;
+ALTER TABLE books DROP COLUMN IF EXISTS price_currency;
+ALTER TABLE books DROP COLUMN IF EXISTS price;
@@ -0,0 +1,7 @@
+-- Prices are stored in the minor unit of their ISO 4217 currency.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS price bigint NOT NULL DEFAULT 0;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS price_currency char(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE books ADD CONSTRAINT books_price_check CHECK (price >= 0);
+
+CREATE INDEX IF NOT EXISTS books_price_idx ON books (price_currency, price, id);
@@ -0,0 +1,34 @@
+-- Books without an inventory row have no copies in stock.
+CREATE TABLE IF NOT EXISTS inventory (
+  book_id bigint PRIMARY KEY REFERENCES books ON DELETE CASCADE,
+  on_hand integer NOT NULL DEFAULT 0,
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  CONSTRAINT inventory_on_hand_check CHECK (on_hand >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+  id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL REFERENCES users,
+  status text NOT NULL DEFAULT 'pending',
+  total bigint NOT NULL,
+  currency char(3) NOT NULL,
+  version integer NOT NULL DEFAULT 1,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id);
+
+-- Order items keep a snapshot of the title and price of each book, so orders
+-- are unaffected by later catalog changes.
+CREATE TABLE IF NOT EXISTS order_items (
+  id bigserial PRIMARY KEY,
+  order_id bigint NOT NULL REFERENCES orders ON DELETE CASCADE,
+  book_id bigint REFERENCES books ON DELETE SET NULL,
+  title text NOT NULL,
+  quantity integer NOT NULL,
+  unit_price bigint NOT NULL,
+  currency char(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_items_order_id_idx ON order_items (order_id);
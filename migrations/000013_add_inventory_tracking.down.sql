@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code = 'inventory:write';
+DROP TABLE IF EXISTS inventory_movements;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_reserved_check;
+ALTER TABLE inventory DROP COLUMN IF EXISTS reserved;
@@ -0,0 +1,23 @@
+ALTER TABLE inventory ADD COLUMN IF NOT EXISTS reserved integer NOT NULL DEFAULT 0;
+
+ALTER TABLE inventory ADD CONSTRAINT inventory_reserved_check
+  CHECK (reserved >= 0 AND reserved <= on_hand);
+
+-- Every change to the on-hand quantity of a book is recorded in the ledger,
+-- either as a staff adjustment or as a sale.
+CREATE TABLE IF NOT EXISTS inventory_movements (
+  id bigserial PRIMARY KEY,
+  book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+  quantity integer NOT NULL,
+  reason text NOT NULL,
+  note text NOT NULL DEFAULT '',
+  order_id bigint REFERENCES orders ON DELETE SET NULL,
+  created_by bigint REFERENCES users ON DELETE SET NULL,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  CONSTRAINT inventory_movements_reason_check
+    CHECK (reason IN ('received', 'damaged', 'correction', 'sale'))
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_book_id_idx ON inventory_movements (book_id, created_at);
+
+INSERT INTO permissions (code) VALUES ('inventory:write');
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code = 'orders:write';
+DROP INDEX IF EXISTS orders_payment_idx;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_provider;
@@ -0,0 +1,11 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_provider text NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_id text NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('pending', 'paid', 'failed', 'refunded'));
+
+-- Webhooks look orders up by the payment the provider reports on.
+CREATE UNIQUE INDEX IF NOT EXISTS orders_payment_idx ON orders (payment_provider, payment_id)
+  WHERE payment_id <> '';
+
+INSERT INTO permissions (code) VALUES ('orders:write');
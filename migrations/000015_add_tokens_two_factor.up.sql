@@ -0,0 +1,4 @@
+-- Authentication tokens record whether they were issued after a second factor
+-- was verified. Existing sessions have to sign in again to reach the routes
+-- that require two-factor authentication.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS two_factor bool NOT NULL DEFAULT false;
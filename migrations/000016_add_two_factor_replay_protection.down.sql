@@ -0,0 +1,2 @@
+ALTER TABLE tokens DROP COLUMN IF EXISTS attempts;
+ALTER TABLE users_totp DROP COLUMN IF EXISTS last_counter;
@@ -0,0 +1,5 @@
+-- The counter of the last accepted code, so a code can't be used twice.
+ALTER TABLE users_totp ADD COLUMN IF NOT EXISTS last_counter bigint NOT NULL DEFAULT 0;
+
+-- Failed attempts to verify a two-factor token, which is burned after a few.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS attempts integer NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders_reserved_until_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS reserved_until;
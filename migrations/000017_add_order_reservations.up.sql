@@ -0,0 +1,10 @@
+-- Pending orders hold their stock until reserved_until, after which they are
+-- failed and their stock released. Orders placed before this migration get a
+-- fresh window.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS reserved_until timestamp(0) with time zone NOT NULL
+  DEFAULT NOW() + INTERVAL '30 minutes';
+
+ALTER TABLE orders ALTER COLUMN reserved_until DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS orders_reserved_until_idx ON orders (reserved_until)
+  WHERE status = 'pending';